@@ -0,0 +1,113 @@
+package functional
+
+import (
+	"slices"
+	"testing"
+)
+
+const benchLength = 10_000_000
+
+func benchInput() []int {
+	values := make([]int, benchLength)
+	for i := range values {
+		values[i] = i
+	}
+	return values
+}
+
+func benchDouble(x int) int { return x * 2 }
+
+func benchNotDivBy3(x int) bool { return x%3 != 0 }
+
+func benchIncrement(x int) int { return x + 1 }
+
+func BenchmarkMapFilterMap(b *testing.B) {
+	input := benchInput()
+
+	b.Run("fused", func(b *testing.B) {
+		for range b.N {
+			sum := 0
+			FromSlice(input).Map(benchDouble).Filter(benchNotDivBy3).Map(benchIncrement).
+				ForEach(func(x int) { sum += x })
+		}
+	})
+	b.Run("nested", func(b *testing.B) {
+		for range b.N {
+			sum := 0
+			seq := nestedMap(nestedFilter(nestedMap(slices.Values(input), benchDouble), benchNotDivBy3), benchIncrement)
+			From(seq).ForEach(func(x int) { sum += x })
+		}
+	})
+}
+
+func BenchmarkLongChain(b *testing.B) {
+	input := benchInput()
+
+	b.Run("fused", func(b *testing.B) {
+		for range b.N {
+			FromSlice(input).
+				Drop(10).Map(benchDouble).Filter(benchNotDivBy3).Map(benchIncrement).
+				Map(benchDouble).Filter(benchNotDivBy3).Take(benchLength / 2).
+				Count()
+		}
+	})
+	b.Run("nested", func(b *testing.B) {
+		for range b.N {
+			seq := nestedDrop(slices.Values(input), 10)
+			seq = nestedFilter(nestedMap(nestedMap(nestedFilter(nestedMap(seq, benchDouble), benchNotDivBy3), benchIncrement), benchDouble), benchNotDivBy3)
+			From(nestedTake(seq, benchLength/2)).Count()
+		}
+	})
+}
+
+func BenchmarkEightMaps(b *testing.B) {
+	input := benchInput()
+
+	b.Run("fused", func(b *testing.B) {
+		for range b.N {
+			s := FromSlice(input)
+			for range 8 {
+				s = s.Map(benchIncrement)
+			}
+			s.Count()
+		}
+	})
+	b.Run("nested", func(b *testing.B) {
+		for range b.N {
+			seq := slices.Values(input)
+			for range 8 {
+				seq = nestedMap(seq, benchIncrement)
+			}
+			From(seq).Count()
+		}
+	})
+}
+
+func BenchmarkFourFilters(b *testing.B) {
+	input := benchInput()
+	predicates := []func(int) bool{
+		func(x int) bool { return x%2 == 0 },
+		func(x int) bool { return x%3 != 0 },
+		func(x int) bool { return x%5 != 0 },
+		func(x int) bool { return x%7 != 0 },
+	}
+
+	b.Run("fused", func(b *testing.B) {
+		for range b.N {
+			s := FromSlice(input)
+			for _, predicate := range predicates {
+				s = s.Filter(predicate)
+			}
+			s.Count()
+		}
+	})
+	b.Run("nested", func(b *testing.B) {
+		for range b.N {
+			seq := slices.Values(input)
+			for _, predicate := range predicates {
+				seq = nestedFilter(seq, predicate)
+			}
+			From(seq).Count()
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package functional
+
+import (
+	"cmp"
+	"strings"
+)
+
+// Number is a constraint that permits any integer or floating-point type.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Collect returns the elements in the stream as a slice.
+func (s *Stream[T]) Collect() []T {
+	var values []T
+	for v := range s.seq {
+		values = append(values, v)
+	}
+	return values
+}
+
+// Count returns the number of elements in the stream.
+func (s *Stream[T]) Count() int {
+	n := 0
+	for range s.seq {
+		n++
+	}
+	return n
+}
+
+// FindFirst returns the first element in the stream.
+//
+// The second value is false if the stream is empty.
+func (s *Stream[T]) FindFirst() (T, bool) {
+	for v := range s.seq {
+		return v, true
+	}
+	var zero T
+	return zero, false
+}
+
+// AnyMatch reports whether any element in the stream satisfies the predicate.
+// Stops at the first such element.
+func (s *Stream[T]) AnyMatch(predicate func(T) bool) bool {
+	for v := range s.seq {
+		if predicate(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllMatch reports whether all elements in the stream satisfy the predicate.
+// Stops at the first element that doesn't. Returns true for an empty stream.
+func (s *Stream[T]) AllMatch(predicate func(T) bool) bool {
+	for v := range s.seq {
+		if !predicate(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// NoneMatch reports whether no element in the stream satisfies the predicate.
+// Stops at the first element that does. Returns true for an empty stream.
+func (s *Stream[T]) NoneMatch(predicate func(T) bool) bool {
+	return !s.AnyMatch(predicate)
+}
+
+// Partition splits the elements in the stream into the ones that satisfy the predicate
+// and the rest, keeping their order.
+func (s *Stream[T]) Partition(predicate func(T) bool) (matched, rest []T) {
+	for v := range s.seq {
+		if predicate(v) {
+			matched = append(matched, v)
+		} else {
+			rest = append(rest, v)
+		}
+	}
+	return matched, rest
+}
+
+// ToMap returns a map from keys to values computed for each element in the stream.
+// Later elements overwrite earlier ones with the same key.
+func ToMap[T any, K comparable, V any](s *Stream[T], key func(T) K, value func(T) V) map[K]V {
+	m := make(map[K]V)
+	for v := range s.seq {
+		m[key(v)] = value(v)
+	}
+	return m
+}
+
+// GroupBy returns a map from keys to the elements in the stream that have them,
+// keeping the order of elements within each group.
+func GroupBy[T any, K comparable](s *Stream[T], key func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	for v := range s.seq {
+		k := key(v)
+		groups[k] = append(groups[k], v)
+	}
+	return groups
+}
+
+// Min returns the smallest element in the stream.
+//
+// The second value is false if the stream is empty.
+func Min[T cmp.Ordered](s *Stream[T]) (T, bool) {
+	return extreme(s, func(a, b T) bool { return a < b })
+}
+
+// Max returns the largest element in the stream.
+//
+// The second value is false if the stream is empty.
+func Max[T cmp.Ordered](s *Stream[T]) (T, bool) {
+	return extreme(s, func(a, b T) bool { return a > b })
+}
+
+func extreme[T any](s *Stream[T], better func(a, b T) bool) (T, bool) {
+	var (
+		result T
+		found  bool
+	)
+	for v := range s.seq {
+		if !found || better(v, result) {
+			result, found = v, true
+		}
+	}
+	return result, found
+}
+
+// Sum returns the sum of the elements in the stream.
+func Sum[T Number](s *Stream[T]) T {
+	var sum T
+	for v := range s.seq {
+		sum += v
+	}
+	return sum
+}
+
+// Average returns the arithmetic mean of the elements in the stream.
+//
+// The second value is false if the stream is empty.
+func Average[T Number](s *Stream[T]) (float64, bool) {
+	var (
+		sum float64
+		n   int
+	)
+	for v := range s.seq {
+		sum += float64(v)
+		n++
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
+
+// Joining concatenates the strings in the stream, placing sep between them.
+func Joining(s *Stream[string], sep string) string {
+	var b strings.Builder
+	first := true
+	for v := range s.seq {
+		if !first {
+			b.WriteString(sep)
+		}
+		b.WriteString(v)
+		first = false
+	}
+	return b.String()
+}
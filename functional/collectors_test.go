@@ -0,0 +1,125 @@
+package functional
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollect(t *testing.T) {
+	require.Equal(t, []int{2, 4}, NewStream(slices.Values([]int{1, 2, 3, 4})).
+		Filter(func(x int) bool { return x%2 == 0 }).
+		Collect())
+	require.Empty(t, NewStream(slices.Values([]int{})).Collect())
+	require.Equal(t, 3, naturals().Take(3).Count())
+}
+
+func TestFindFirst(t *testing.T) {
+	v, ok := naturals().Filter(func(x int) bool { return x > 10 }).FindFirst()
+	require.True(t, ok)
+	require.Equal(t, 11, v)
+
+	_, ok = NewStream(slices.Values([]int{})).FindFirst()
+	require.False(t, ok)
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     []int
+		anyMatch  bool
+		allMatch  bool
+		noneMatch bool
+	}{
+		{
+			name:     "some_even",
+			input:    []int{1, 2, 3},
+			anyMatch: true,
+		},
+		{
+			name:     "all_even",
+			input:    []int{2, 4},
+			anyMatch: true,
+			allMatch: true,
+		},
+		{
+			name:      "no_even",
+			input:     []int{1, 3},
+			noneMatch: true,
+		},
+		{
+			name:      "empty",
+			input:     []int{},
+			allMatch:  true,
+			noneMatch: true,
+		},
+	}
+
+	even := func(x int) bool { return x%2 == 0 }
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stream := NewStream(slices.Values(tc.input))
+			require.Equal(t, tc.anyMatch, stream.AnyMatch(even))
+			require.Equal(t, tc.allMatch, stream.AllMatch(even))
+			require.Equal(t, tc.noneMatch, stream.NoneMatch(even))
+		})
+	}
+
+	// Short-circuiting makes matching work on infinite streams.
+	require.True(t, naturals().AnyMatch(func(x int) bool { return x > 100 }))
+	require.False(t, naturals().AllMatch(func(x int) bool { return x < 100 }))
+	require.False(t, naturals().NoneMatch(func(x int) bool { return x == 100 }))
+}
+
+func TestPartition(t *testing.T) {
+	even, odd := naturals().Take(6).Partition(func(x int) bool { return x%2 == 0 })
+	require.Equal(t, []int{2, 4, 6}, even)
+	require.Equal(t, []int{1, 3, 5}, odd)
+}
+
+func TestToMapGroupBy(t *testing.T) {
+	words := From(slices.Values([]string{"apple", "avocado", "banana", "blueberry", "cherry"}))
+
+	lengths := ToMap(words, func(s string) string { return s }, func(s string) int { return len(s) })
+	require.Equal(t, map[string]int{"apple": 5, "avocado": 7, "banana": 6, "blueberry": 9, "cherry": 6}, lengths)
+
+	groups := GroupBy(words, func(s string) byte { return s[0] })
+	require.Equal(t, map[byte][]string{
+		'a': {"apple", "avocado"},
+		'b': {"banana", "blueberry"},
+		'c': {"cherry"},
+	}, groups)
+}
+
+func TestStatistics(t *testing.T) {
+	stream := NewStream(slices.Values([]int{3, -1, 4, 1, 5}))
+
+	minValue, ok := Min(stream)
+	require.True(t, ok)
+	require.Equal(t, -1, minValue)
+
+	maxValue, ok := Max(stream)
+	require.True(t, ok)
+	require.Equal(t, 5, maxValue)
+
+	require.Equal(t, 12, Sum(stream))
+
+	avg, ok := Average(stream)
+	require.True(t, ok)
+	require.InDelta(t, 2.4, avg, 1e-9)
+
+	empty := From(slices.Values([]float64{}))
+	_, ok = Min(empty)
+	require.False(t, ok)
+	_, ok = Average(empty)
+	require.False(t, ok)
+	require.Zero(t, Sum(empty))
+}
+
+func TestJoining(t *testing.T) {
+	words := Map(naturals().Take(3), func(x int) string { return strings.Repeat("*", x) })
+	require.Equal(t, "*, **, ***", Joining(words, ", "))
+	require.Empty(t, Joining(From(slices.Values([]string{})), ", "))
+}
@@ -2,56 +2,207 @@ package functional
 
 import (
 	"iter"
+	"slices"
 )
 
-// Stream represents a sequence of integers that can be processed using functional operations.
-type Stream struct {
-	// TODO: Add your fields here
+// Stream represents a lazy sequence of values that can be processed using functional operations.
+//
+// Operations that change the element type, like Map, are free functions,
+// because methods can't have type parameters.
+type Stream[T any] struct {
+	seq iter.Seq[T]
+
+	// workers is the number of goroutines Map and Filter run on,
+	// values below 2 mean sequential execution.
+	workers int
+	// unordered allows parallel Map and Filter to emit elements
+	// as soon as they are ready instead of in the input order.
+	unordered bool
+	// clock is used by time-based operators, nil means the system clock.
+	clock Clock
+	// tracer records statistics of the stages of the stream, if set.
+	tracer *Tracer
+	// stage is the tracer record of the operation that produced the stream.
+	stage *stageStats
+	// fused holds the operations fused into seq, if it was built by fuse.
+	fused *fusion[T]
+}
+
+// From creates a new Stream from a sequence of values.
+func From[T any](values iter.Seq[T]) *Stream[T] {
+	return &Stream[T]{seq: values}
+}
+
+// NewStream creates a new Stream from a sequence of integers.
+func NewStream(values iter.Seq[int]) *Stream[int] {
+	return From(values)
+}
+
+// derive creates a stream producing values with the operation name,
+// which inherits settings of s.
+func derive[T, U any](s *Stream[T], name string, values iter.Seq[U]) *Stream[U] {
+	d := &Stream[U]{
+		seq:       values,
+		workers:   s.workers,
+		unordered: s.unordered,
+		clock:     s.clock,
+		tracer:    s.tracer,
+	}
+	if s.tracer != nil {
+		d.stage = s.tracer.addStage(name)
+		d.seq = traced(s.tracer, d.stage, values)
+	}
+	return d
+}
+
+// clone returns a copy of the stream to change its settings.
+func (s *Stream[T]) clone() *Stream[T] {
+	c := *s
+	return &c
 }
 
-// NewStream creates a new Stream from a slice of integers.
-func NewStream(values iter.Seq[int]) *Stream {
-	// TODO: Implement this function
-	return nil
+// Map applies the function f to each element in the stream and returns a new stream with the results.
+func Map[T, U any](s *Stream[T], f func(T) U) *Stream[U] {
+	if s.workers > 1 {
+		return derive(s, "Map", parallel(s, func(v T) (U, bool) { return f(v), true }))
+	}
+	return derive(s, "Map", func(yield func(U) bool) {
+		for v := range s.seq {
+			if !yield(f(v)) {
+				return
+			}
+		}
+	})
 }
 
 // Map applies the function f to each element in the stream and returns a new stream with the results.
-func (s *Stream) Map(f func(int) int) *Stream {
-	// TODO: Implement this function
-	return nil
+//
+// Consecutive Map, Filter, Take and Drop operations of a sequential stream are fused
+// and run in a single loop.
+func (s *Stream[T]) Map(f func(T) T) *Stream[T] {
+	if s.fusible() {
+		return s.fuse(fusedMap(f))
+	}
+	return Map(s, f)
 }
 
 // Filter returns a new stream containing only the elements that satisfy the predicate.
-func (s *Stream) Filter(predicate func(int) bool) *Stream {
-	// TODO: Implement this function
-	return nil
+func (s *Stream[T]) Filter(predicate func(T) bool) *Stream[T] {
+	if s.workers > 1 {
+		return derive(s, "Filter", parallel(s, func(v T) (T, bool) { return v, predicate(v) }))
+	}
+	if s.fusible() {
+		return s.fuse(fusedFilter(predicate))
+	}
+	return derive(s, "Filter", func(yield func(T) bool) {
+		for v := range s.seq {
+			if predicate(v) && !yield(v) {
+				return
+			}
+		}
+	})
 }
 
 // Take returns a new stream containing at most the first n elements.
-func (s *Stream) Take(n int) *Stream {
-	// TODO: Implement this function
-	return nil
+func (s *Stream[T]) Take(n int) *Stream[T] {
+	if s.fusible() {
+		return s.fuse(fusedTake[T](n))
+	}
+	return derive(s, "Take", func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		taken := 0
+		for v := range s.seq {
+			if !yield(v) {
+				return
+			}
+			taken++
+			if taken == n {
+				return
+			}
+		}
+	})
 }
 
 // Drop returns a new stream with the first n elements removed.
-func (s *Stream) Drop(n int) *Stream {
-	// TODO: Implement this function
-	return nil
+func (s *Stream[T]) Drop(n int) *Stream[T] {
+	if s.fusible() {
+		return s.fuse(fusedDrop[T](n))
+	}
+	return derive(s, "Drop", func(yield func(T) bool) {
+		dropped := 0
+		for v := range s.seq {
+			if dropped < n {
+				dropped++
+				continue
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	})
 }
 
 // Iterate returns an iterator over the elements in the stream.
-func (s *Stream) Iterate() iter.Seq[int] {
-	// TODO: Implement this function
-	return nil
+func (s *Stream[T]) Iterate() iter.Seq[T] {
+	return s.seq
 }
 
 // FoldLeft reduces the stream to a single value using the function f, processing from left to right.
-func (s *Stream) FoldLeft(f func(int, int) int) int {
-	// TODO: Implement this function
-	return 0
+//
+// The first element is used as the initial value. Returns the zero value of T if the stream is empty.
+//
+// Use Reduce to tell an empty stream apart, or Fold to start from a value of another type.
+func (s *Stream[T]) FoldLeft(f func(T, T) T) T {
+	acc, _ := s.Reduce(f)
+	return acc
+}
+
+// Reduce reduces the stream to a single value using the function f, processing from left to right
+// and starting with the first element.
+//
+// The second value is false if the stream is empty.
+func (s *Stream[T]) Reduce(f func(T, T) T) (T, bool) {
+	var acc T
+	found := false
+	for v := range s.seq {
+		if !found {
+			acc, found = v, true
+			continue
+		}
+		acc = f(acc, v)
+	}
+	return acc, found
+}
+
+// Fold reduces the stream to a value of type A using the function f, processing from left to right
+// and starting with seed. Returns seed if the stream is empty.
+func Fold[T, A any](s *Stream[T], seed A, f func(A, T) A) A {
+	acc := seed
+	for v := range s.seq {
+		acc = f(acc, v)
+	}
+	return acc
+}
+
+// FoldRight reduces the stream to a value of type A using the function f, processing from right to left
+// and starting with seed. Returns seed if the stream is empty.
+//
+// The stream must be finite. Its elements are buffered in a slice and folded in a loop,
+// so the call stack doesn't grow with the length of the stream.
+func FoldRight[T, A any](s *Stream[T], seed A, f func(T, A) A) A {
+	values := slices.Collect(s.seq)
+	acc := seed
+	for i := len(values) - 1; i >= 0; i-- {
+		acc = f(values[i], acc)
+	}
+	return acc
 }
 
 // ForEach applies the function f to each element in the stream.
-func (s *Stream) ForEach(f func(int)) {
-	// TODO: Implement this function
+func (s *Stream[T]) ForEach(f func(T)) {
+	for v := range s.seq {
+		f(v)
+	}
 }
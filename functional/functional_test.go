@@ -1,6 +1,7 @@
 package functional
 
 import (
+	"fmt"
 	"iter"
 	"slices"
 	"testing"
@@ -308,3 +309,85 @@ func TestStreamCombined(t *testing.T) {
 	result := slices.Collect(it)
 	require.Equal(t, []int{10, 16}, result)
 }
+
+func TestGenericMap(t *testing.T) {
+	type point struct{ x, y int }
+
+	stream := NewStream(slices.Values([]int{1, 2, 3}))
+	points := Map(stream, func(x int) point { return point{x, x * x} })
+	labels := Map(points, func(p point) string { return fmt.Sprintf("(%d,%d)", p.x, p.y) })
+
+	result := slices.Collect(labels.Filter(func(s string) bool { return s != "(2,4)" }).Iterate())
+	require.Equal(t, []string{"(1,1)", "(3,9)"}, result)
+}
+
+func TestStreamReusable(t *testing.T) {
+	stream := From(slices.Values([]string{"a", "b", "c", "d"})).Drop(1).Take(2)
+
+	require.Equal(t, []string{"b", "c"}, slices.Collect(stream.Iterate()))
+	require.Equal(t, []string{"b", "c"}, slices.Collect(stream.Iterate()))
+}
+
+func TestStreamReduce(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []int
+		expected int
+		ok       bool
+	}{
+		{
+			name:     "max",
+			input:    []int{3, 7, 2},
+			expected: 7,
+			ok:       true,
+		},
+		{
+			name:     "single_zero",
+			input:    []int{0},
+			expected: 0,
+			ok:       true,
+		},
+		{
+			name:     "empty_stream",
+			input:    []int{},
+			expected: 0,
+			ok:       false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stream := NewStream(slices.Values(tc.input))
+			result, ok := stream.Reduce(func(acc, x int) int { return max(acc, x) })
+			require.Equal(t, tc.expected, result)
+			require.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestFold(t *testing.T) {
+	stream := NewStream(slices.Values([]int{1, 2, 3}))
+
+	digits := Fold(stream, "", func(acc string, x int) string { return acc + fmt.Sprint(x) })
+	require.Equal(t, "123", digits)
+
+	product := Fold(stream, 1, func(acc, x int) int { return acc * x })
+	require.Equal(t, 6, product)
+
+	empty := Fold(NewStream(slices.Values([]int{})), 42, func(acc, x int) int { return acc + x })
+	require.Equal(t, 42, empty)
+}
+
+func TestFoldRight(t *testing.T) {
+	stream := NewStream(slices.Values([]int{100, 10, 2}))
+
+	// 100 - (10 - (2 - 0))
+	require.Equal(t, 92, FoldRight(stream, 0, func(x, acc int) int { return x - acc }))
+
+	digits := FoldRight(stream, "", func(x int, acc string) string { return acc + fmt.Sprint(x) })
+	require.Equal(t, "210100", digits)
+
+	// Long streams don't overflow the stack.
+	count := FoldRight(naturals().Take(1_000_000), 0, func(_, acc int) int { return acc + 1 })
+	require.Equal(t, 1_000_000, count)
+}
@@ -0,0 +1,211 @@
+package functional
+
+import "iter"
+
+// sinkWrapper returns the function passing an element through operations
+// to next and returning false to stop. It is called once per iteration,
+// so the returned function may keep the state of the iteration.
+type sinkWrapper[T any] func(next func(T) bool) func(T) bool
+
+// fusedOp is a Map, Filter, Take or Drop operation recorded for fusion.
+// Exactly one of mapper, predicate and wrap is set.
+type fusedOp[T any] struct {
+	mapper    func(T) T
+	predicate func(T) bool
+	// wrap implements operations with state, like Take.
+	wrap sinkWrapper[T]
+	// empty is set if the operation passes no elements, like Take(0).
+	empty bool
+}
+
+func fusedMap[T any](mapper func(T) T) fusedOp[T] {
+	return fusedOp[T]{mapper: mapper}
+}
+
+func fusedFilter[T any](predicate func(T) bool) fusedOp[T] {
+	return fusedOp[T]{predicate: predicate}
+}
+
+func fusedTake[T any](n int) fusedOp[T] {
+	return fusedOp[T]{empty: n <= 0, wrap: func(next func(T) bool) func(T) bool {
+		taken := 0
+		return func(v T) bool {
+			taken++
+			// Like Take, stop right after the last element without
+			// reading the next one from the source.
+			return next(v) && taken < n
+		}
+	}}
+}
+
+func fusedDrop[T any](n int) fusedOp[T] {
+	return fusedOp[T]{wrap: func(next func(T) bool) func(T) bool {
+		dropped := 0
+		return func(v T) bool {
+			if dropped < n {
+				dropped++
+				return true
+			}
+			return next(v)
+		}
+	}}
+}
+
+// fusion is a chain of operations that don't change the element type,
+// applied to the source of a stream as a whole instead of one nested
+// iterator per stage.
+//
+// The operations are composed when the stream is built into a chain of
+// functions, each passing an element on to the next one, so that iterating
+// only creates the state of Take and Drop. Every function of the chain
+// applies two consecutive Maps or Filters, with a body of its own for each
+// combination of them. That halves the calls between stages compared to
+// nested iterators, and keeps every call site with a single target, which
+// the CPU predicts.
+type fusion[T any] struct {
+	source iter.Seq[T]
+	// slice holds the elements of the source if it is a slice.
+	slice  []T
+	sliced bool
+
+	// outer composes the operations before pending, nil if there are none.
+	outer sinkWrapper[T]
+	// pending is the last Map or Filter, if it isn't paired with the one
+	// before it yet.
+	pending *fusedOp[T]
+	// sink composes all the operations, nil if there are none.
+	sink sinkWrapper[T]
+	// empty is set if the operations pass no elements.
+	empty bool
+}
+
+// fusible reports whether operations on s can be fused. Parallel streams
+// run Map and Filter on workers, and traced streams need every stage separately.
+func (s *Stream[T]) fusible() bool {
+	return s.workers <= 1 && s.tracer == nil
+}
+
+// fuse returns a new stream applying op after the operations
+// fused into s, if any.
+func (s *Stream[T]) fuse(op fusedOp[T]) *Stream[T] {
+	f := &fusion[T]{source: s.seq}
+	if s.fused != nil {
+		*f = *s.fused
+	}
+	switch {
+	case op.wrap != nil:
+		f.outer = chainSinks(chainSinks(f.outer, stageSink(f.pending)), op.wrap)
+		f.pending = nil
+	case f.pending != nil:
+		f.outer = chainSinks(f.outer, pairSink(*f.pending, op))
+		f.pending = nil
+	default:
+		f.pending = &op
+	}
+	f.sink = chainSinks(f.outer, stageSink(f.pending))
+	f.empty = f.empty || op.empty
+
+	d := s.clone()
+	d.seq = f.seq
+	d.fused = f
+	d.stage = nil
+	return d
+}
+
+// stageSink returns a wrapper applying a single Map or Filter,
+// nil if op is nil.
+func stageSink[T any](op *fusedOp[T]) sinkWrapper[T] {
+	switch {
+	case op == nil:
+		return nil
+	case op.mapper != nil:
+		mapper := op.mapper
+		return func(next func(T) bool) func(T) bool {
+			return func(v T) bool {
+				return next(mapper(v))
+			}
+		}
+	default:
+		predicate := op.predicate
+		return func(next func(T) bool) func(T) bool {
+			return func(v T) bool {
+				return !predicate(v) || next(v)
+			}
+		}
+	}
+}
+
+// pairSink returns a wrapper applying two consecutive Maps or Filters.
+func pairSink[T any](first, second fusedOp[T]) sinkWrapper[T] {
+	switch {
+	case first.mapper != nil && second.mapper != nil:
+		m1, m2 := first.mapper, second.mapper
+		return func(next func(T) bool) func(T) bool {
+			return func(v T) bool {
+				return next(m2(m1(v)))
+			}
+		}
+	case first.mapper != nil:
+		mapper, predicate := first.mapper, second.predicate
+		return func(next func(T) bool) func(T) bool {
+			return func(v T) bool {
+				v = mapper(v)
+				return !predicate(v) || next(v)
+			}
+		}
+	case second.mapper != nil:
+		predicate, mapper := first.predicate, second.mapper
+		return func(next func(T) bool) func(T) bool {
+			return func(v T) bool {
+				return !predicate(v) || next(mapper(v))
+			}
+		}
+	default:
+		p1, p2 := first.predicate, second.predicate
+		return func(next func(T) bool) func(T) bool {
+			return func(v T) bool {
+				return !p1(v) || !p2(v) || next(v)
+			}
+		}
+	}
+}
+
+// chainSinks returns a wrapper passing elements through first and then
+// through then, or the one that is set if the other is nil.
+func chainSinks[T any](first, then sinkWrapper[T]) sinkWrapper[T] {
+	switch {
+	case first == nil:
+		return then
+	case then == nil:
+		return first
+	}
+	return func(next func(T) bool) func(T) bool {
+		return first(then(next))
+	}
+}
+
+// seq iterates over the source, passing its elements through the operations.
+// Unlike nested iterators, the operations are driven by a single loop.
+func (f *fusion[T]) seq(yield func(T) bool) {
+	if f.empty {
+		return
+	}
+	sink := yield
+	if f.sink != nil {
+		sink = f.sink(yield)
+	}
+
+	if f.sliced {
+		for _, v := range f.slice {
+			if !sink(v) {
+				return
+			}
+		}
+		return
+	}
+	for v := range f.source {
+		if !sink(v) {
+			return
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package functional
+
+import (
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// nestedMap, nestedFilter, nestedTake and nestedDrop are the straightforward
+// one closure per stage operations, kept as a baseline for fusion.
+func nestedMap[T any](seq iter.Seq[T], f func(T) T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if !yield(f(v)) {
+				return
+			}
+		}
+	}
+}
+
+func nestedFilter[T any](seq iter.Seq[T], predicate func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if predicate(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func nestedTake[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		taken := 0
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+			taken++
+			if taken == n {
+				return
+			}
+		}
+	}
+}
+
+func nestedDrop[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		dropped := 0
+		for v := range seq {
+			if dropped < n {
+				dropped++
+				continue
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func TestFusion(t *testing.T) {
+	double := func(x int) int { return x * 2 }
+	inc := func(x int) int { return x + 1 }
+	odd := func(x int) bool { return x%2 != 0 }
+	notDivBy3 := func(x int) bool { return x%3 != 0 }
+
+	for _, tc := range []struct {
+		name   string
+		fused  func(*Stream[int]) *Stream[int]
+		nested func(iter.Seq[int]) iter.Seq[int]
+	}{
+		{
+			name:  "map filter map",
+			fused: func(s *Stream[int]) *Stream[int] { return s.Map(double).Filter(notDivBy3).Map(double) },
+			nested: func(s iter.Seq[int]) iter.Seq[int] {
+				return nestedMap(nestedFilter(nestedMap(s, double), notDivBy3), double)
+			},
+		},
+		{
+			name:   "take then drop",
+			fused:  func(s *Stream[int]) *Stream[int] { return s.Take(10).Drop(3).Filter(odd) },
+			nested: func(s iter.Seq[int]) iter.Seq[int] { return nestedFilter(nestedDrop(nestedTake(s, 10), 3), odd) },
+		},
+		{
+			name:  "drop then take",
+			fused: func(s *Stream[int]) *Stream[int] { return s.Filter(odd).Drop(3).Take(4).Map(double) },
+			nested: func(s iter.Seq[int]) iter.Seq[int] {
+				return nestedMap(nestedTake(nestedDrop(nestedFilter(s, odd), 3), 4), double)
+			},
+		},
+		{
+			name:   "take zero",
+			fused:  func(s *Stream[int]) *Stream[int] { return s.Map(double).Take(0) },
+			nested: func(s iter.Seq[int]) iter.Seq[int] { return nestedTake(nestedMap(s, double), 0) },
+		},
+		{
+			name: "runs",
+			fused: func(s *Stream[int]) *Stream[int] {
+				return s.Map(double).Map(inc).Filter(odd).Filter(notDivBy3).Map(double)
+			},
+			nested: func(s iter.Seq[int]) iter.Seq[int] {
+				return nestedMap(nestedFilter(nestedFilter(nestedMap(nestedMap(s, double), inc), odd), notDivBy3), double)
+			},
+		},
+		{
+			name:   "repeated take",
+			fused:  func(s *Stream[int]) *Stream[int] { return s.Take(5).Filter(notDivBy3).Take(2) },
+			nested: func(s iter.Seq[int]) iter.Seq[int] { return nestedTake(nestedFilter(nestedTake(s, 5), notDivBy3), 2) },
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var fusedPulled, nestedPulled []int
+			fused := tc.fused(naturals().Peek(func(x int) { fusedPulled = append(fusedPulled, x) }))
+			nested := tc.nested(naturals().Peek(func(x int) { nestedPulled = append(nestedPulled, x) }).Iterate())
+
+			expected := slices.Collect(nestedTake(nested, 100))
+			require.Equal(t, expected, fused.Take(100).Collect())
+			require.Equal(t, nestedPulled, fusedPulled)
+
+			// Streams of slices are iterated without their source.
+			input := naturals().Take(100).Collect()
+			var slicePulled []int
+			nestedPulled = nil
+			sliced := tc.fused(FromSlice(input).Map(func(x int) int {
+				slicePulled = append(slicePulled, x)
+				return x
+			}))
+			nested = tc.nested(FromSlice(input).Peek(func(x int) { nestedPulled = append(nestedPulled, x) }).Iterate())
+
+			require.Equal(t, slices.Collect(nested), sliced.Collect())
+			require.Equal(t, nestedPulled, slicePulled)
+		})
+	}
+}
+
+func TestFusionSharedPrefix(t *testing.T) {
+	base := From(slices.Values([]int{1, 2, 3, 4, 5, 6})).Filter(func(x int) bool { return x > 1 })
+	evens := base.Filter(func(x int) bool { return x%2 == 0 })
+	firstTwo := base.Take(2)
+
+	require.Equal(t, []int{2, 4, 6}, evens.Collect())
+	require.Equal(t, []int{2, 3}, firstTwo.Collect())
+	require.Equal(t, []int{2, 3, 4, 5, 6}, base.Collect())
+
+	mapped := FromSlice([]int{1, 2, 3}).Map(func(x int) int { return x * 10 })
+	plusOne := mapped.Map(func(x int) int { return x + 1 })
+	plusTwo := mapped.Map(func(x int) int { return x + 2 })
+	require.Equal(t, []int{11, 21, 31}, plusOne.Collect())
+	require.Equal(t, []int{12, 22, 32}, plusTwo.Collect())
+	require.Equal(t, []int{10, 20, 30}, mapped.Collect())
+}
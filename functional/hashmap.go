@@ -0,0 +1,518 @@
+package functional
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"iter"
+	"math"
+	"math/bits"
+	"reflect"
+	"slices"
+)
+
+const (
+	hashBits = 5
+	hashMask = 1<<hashBits - 1
+	// hashLevels is the number of trie levels that consume bits of 64-bit hashes,
+	// keys with equal hashes are kept in collision nodes below them.
+	hashLevels = (64 + hashBits - 1) / hashBits
+)
+
+var hashSeed = maphash.MakeSeed()
+
+// HashMap is an immutable map. Methods that change the map return a new one,
+// sharing most of its structure with the original, so they take effectively
+// constant time.
+//
+// Entries are stored in a hash array mapped trie. Keys of basic types are hashed
+// directly, other keys are hashed by walking them with reflection the way == compares
+// them: pointers and channels by address, floats by value, interfaces by their dynamic
+// type and value. That is slower, so NewHashMapFunc may be worth it for such keys.
+// The zero HashMap is empty and ready to use.
+//
+// The iteration order is unspecified. Use a TransientHashMap to build a map
+// from many entries.
+type HashMap[K comparable, V any] struct {
+	count int
+	root  *hashNode[K, V]
+	hash  func(K) uint64
+}
+
+type hashNode[K comparable, V any] struct {
+	edit *editToken
+	// bitmap has a bit set for every 5-bit hash fragment present at the level,
+	// slots hold them in the order of fragments.
+	bitmap uint32
+	slots  []hashSlot[K, V]
+}
+
+// hashSlot holds either a subtree or a single entry.
+type hashSlot[K comparable, V any] struct {
+	node  *hashNode[K, V]
+	hash  uint64
+	key   K
+	value V
+}
+
+// NewHashMapFunc creates an empty HashMap that hashes keys with the function hash.
+// Equal keys must have equal hashes.
+func NewHashMapFunc[K comparable, V any](hash func(K) uint64) HashMap[K, V] {
+	return HashMap[K, V]{hash: hash}
+}
+
+// CollectHashMap creates a new HashMap with the key-value pairs of the sequence.
+// Later values of repeated keys replace earlier ones.
+func CollectHashMap[K comparable, V any](entries iter.Seq2[K, V]) HashMap[K, V] {
+	t := HashMap[K, V]{}.Transient()
+	for k, v := range entries {
+		t.Set(k, v)
+	}
+	return t.Persistent()
+}
+
+// Len returns the number of entries in the map.
+func (m HashMap[K, V]) Len() int {
+	return m.count
+}
+
+// Get returns the value associated with the key.
+//
+// The second value is true if the key exists in the map, and false if not.
+func (m HashMap[K, V]) Get(key K) (V, bool) {
+	if m.root == nil {
+		var zero V
+		return zero, false
+	}
+	return m.root.get(m.hashOf(key), 0, key)
+}
+
+// Contains reports whether the key exists in the map.
+func (m HashMap[K, V]) Contains(key K) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// Set returns a new map with the value associated with the key.
+func (m HashMap[K, V]) Set(key K, value V) HashMap[K, V] {
+	added := false
+	m.root = setInHashNode(nil, m.root, m.hashOf(key), 0, key, value, &added)
+	if added {
+		m.count++
+	}
+	return m
+}
+
+// Delete returns a new map without the key.
+func (m HashMap[K, V]) Delete(key K) HashMap[K, V] {
+	if m.root == nil {
+		return m
+	}
+	removed := false
+	root := deleteFromHashNode(nil, m.root, m.hashOf(key), 0, key, &removed)
+	if !removed {
+		return m
+	}
+	m.root = root
+	m.count--
+	return m
+}
+
+// All returns an iterator over key-value pairs of the map.
+func (m HashMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if m.root != nil {
+			m.root.all(yield)
+		}
+	}
+}
+
+// Keys returns an iterator over keys of the map.
+func (m HashMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over values of the map.
+func (m HashMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Transient returns a TransientHashMap with the entries of the map.
+// The map itself is not affected by changes of the transient.
+func (m HashMap[K, V]) Transient() *TransientHashMap[K, V] {
+	return &TransientHashMap[K, V]{edit: &editToken{}, m: m}
+}
+
+func (m HashMap[K, V]) hashOf(key K) uint64 {
+	if m.hash != nil {
+		return m.hash(key)
+	}
+	return hashKey(key)
+}
+
+// TransientHashMap is a mutable builder of a HashMap. It updates nodes it
+// has created in place, which makes bulk changes much faster than
+// with a HashMap, and shares the rest with the map it was created from.
+//
+// A TransientHashMap must not be used after Persistent is called,
+// and it is not safe for concurrent use.
+type TransientHashMap[K comparable, V any] struct {
+	edit *editToken
+	m    HashMap[K, V]
+}
+
+// Len returns the number of entries in the transient.
+func (t *TransientHashMap[K, V]) Len() int {
+	t.ensureEditable()
+	return t.m.count
+}
+
+// Get returns the value associated with the key.
+//
+// The second value is true if the key exists, and false if not.
+func (t *TransientHashMap[K, V]) Get(key K) (V, bool) {
+	t.ensureEditable()
+	return t.m.Get(key)
+}
+
+// Set associates the value with the key.
+func (t *TransientHashMap[K, V]) Set(key K, value V) {
+	t.ensureEditable()
+	added := false
+	t.m.root = setInHashNode(t.edit, t.m.root, t.m.hashOf(key), 0, key, value, &added)
+	if added {
+		t.m.count++
+	}
+}
+
+// Delete removes the key.
+func (t *TransientHashMap[K, V]) Delete(key K) {
+	t.ensureEditable()
+	if t.m.root == nil {
+		return
+	}
+	removed := false
+	t.m.root = deleteFromHashNode(t.edit, t.m.root, t.m.hashOf(key), 0, key, &removed)
+	if removed {
+		t.m.count--
+	}
+}
+
+// Persistent returns a HashMap with the entries of the transient
+// and invalidates the transient.
+func (t *TransientHashMap[K, V]) Persistent() HashMap[K, V] {
+	t.ensureEditable()
+	t.edit = nil
+	return t.m
+}
+
+func (t *TransientHashMap[K, V]) ensureEditable() {
+	if t.edit == nil {
+		panic("functional: transient used after Persistent")
+	}
+}
+
+// fragment returns the bit of the hash fragment at the level.
+func fragment(hash uint64, level int) uint32 {
+	return 1 << ((hash >> (level * hashBits)) & hashMask)
+}
+
+// index returns the position of the slot for bit in the node.
+func (n *hashNode[K, V]) index(bit uint32) int {
+	return bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+func (n *hashNode[K, V]) get(hash uint64, level int, key K) (V, bool) {
+	for ; level < hashLevels; level++ {
+		bit := fragment(hash, level)
+		if n.bitmap&bit == 0 {
+			break
+		}
+		slot := &n.slots[n.index(bit)]
+		if slot.node == nil {
+			if slot.key == key {
+				return slot.value, true
+			}
+			break
+		}
+		n = slot.node
+	}
+	if level == hashLevels {
+		// Collision nodes keep their entries unordered.
+		for _, slot := range n.slots {
+			if slot.key == key {
+				return slot.value, true
+			}
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+func (n *hashNode[K, V]) all(yield func(K, V) bool) bool {
+	for _, slot := range n.slots {
+		if slot.node != nil {
+			if !slot.node.all(yield) {
+				return false
+			}
+		} else if !yield(slot.key, slot.value) {
+			return false
+		}
+	}
+	return true
+}
+
+func editableHashNode[K comparable, V any](edit *editToken, n *hashNode[K, V]) *hashNode[K, V] {
+	if n == nil {
+		return &hashNode[K, V]{edit: edit}
+	}
+	if edit != nil && n.edit == edit {
+		return n
+	}
+	return &hashNode[K, V]{edit: edit, bitmap: n.bitmap, slots: slices.Clone(n.slots)}
+}
+
+func setInHashNode[K comparable, V any](
+	edit *editToken, n *hashNode[K, V], hash uint64, level int, key K, value V, added *bool,
+) *hashNode[K, V] {
+	if level == hashLevels {
+		if n != nil {
+			for i, slot := range n.slots {
+				if slot.key == key {
+					n = editableHashNode(edit, n)
+					n.slots[i].value = value
+					return n
+				}
+			}
+		}
+		n = editableHashNode(edit, n)
+		n.slots = append(n.slots, hashSlot[K, V]{hash: hash, key: key, value: value})
+		*added = true
+		return n
+	}
+
+	bit := fragment(hash, level)
+	if n == nil || n.bitmap&bit == 0 {
+		n = editableHashNode(edit, n)
+		n.bitmap |= bit
+		n.slots = slices.Insert(n.slots, n.index(bit), hashSlot[K, V]{hash: hash, key: key, value: value})
+		*added = true
+		return n
+	}
+
+	i := n.index(bit)
+	slot := n.slots[i]
+	switch {
+	case slot.node != nil:
+		child := setInHashNode(edit, slot.node, hash, level+1, key, value, added)
+		if child == slot.node {
+			return n
+		}
+		n = editableHashNode(edit, n)
+		n.slots[i].node = child
+	case slot.key == key:
+		n = editableHashNode(edit, n)
+		n.slots[i].value = value
+	default:
+		// Both entries move to a new subtree, which splits them
+		// at the first level where their hashes differ.
+		var child *hashNode[K, V]
+		ignored := false
+		child = setInHashNode(edit, child, slot.hash, level+1, slot.key, slot.value, &ignored)
+		child = setInHashNode(edit, child, hash, level+1, key, value, added)
+		n = editableHashNode(edit, n)
+		n.slots[i] = hashSlot[K, V]{node: child}
+	}
+	return n
+}
+
+// deleteFromHashNode returns the node without the key, or nil if it becomes empty.
+func deleteFromHashNode[K comparable, V any](
+	edit *editToken, n *hashNode[K, V], hash uint64, level int, key K, removed *bool,
+) *hashNode[K, V] {
+	if level == hashLevels {
+		for i, slot := range n.slots {
+			if slot.key == key {
+				*removed = true
+				if len(n.slots) == 1 {
+					return nil
+				}
+				n = editableHashNode(edit, n)
+				n.slots = slices.Delete(n.slots, i, i+1)
+				return n
+			}
+		}
+		return n
+	}
+
+	bit := fragment(hash, level)
+	if n.bitmap&bit == 0 {
+		return n
+	}
+	i := n.index(bit)
+	slot := n.slots[i]
+	if slot.node == nil {
+		if slot.key != key {
+			return n
+		}
+		*removed = true
+		if len(n.slots) == 1 {
+			return nil
+		}
+		n = editableHashNode(edit, n)
+		n.bitmap &^= bit
+		n.slots = slices.Delete(n.slots, i, i+1)
+		return n
+	}
+
+	child := deleteFromHashNode(edit, slot.node, hash, level+1, key, removed)
+	if child == slot.node {
+		return n
+	}
+	if child == nil {
+		if len(n.slots) == 1 {
+			return nil
+		}
+		n = editableHashNode(edit, n)
+		n.bitmap &^= bit
+		n.slots = slices.Delete(n.slots, i, i+1)
+		return n
+	}
+	n = editableHashNode(edit, n)
+	if len(child.slots) == 1 && child.slots[0].node == nil {
+		// A subtree with a single entry collapses into it.
+		n.slots[i] = child.slots[0]
+	} else {
+		n.slots[i].node = child
+	}
+	return n
+}
+
+// hashKey hashes keys of basic types directly and others with hashValue.
+func hashKey[K comparable](key K) uint64 {
+	var buf [8]byte
+	switch k := any(key).(type) {
+	case string:
+		return maphash.String(hashSeed, k)
+	case int:
+		return hashUint64(&buf, uint64(k))
+	case int8:
+		return hashUint64(&buf, uint64(k))
+	case int16:
+		return hashUint64(&buf, uint64(k))
+	case int32:
+		return hashUint64(&buf, uint64(k))
+	case int64:
+		return hashUint64(&buf, uint64(k))
+	case uint:
+		return hashUint64(&buf, uint64(k))
+	case uint8:
+		return hashUint64(&buf, uint64(k))
+	case uint16:
+		return hashUint64(&buf, uint64(k))
+	case uint32:
+		return hashUint64(&buf, uint64(k))
+	case uint64:
+		return hashUint64(&buf, k)
+	case uintptr:
+		return hashUint64(&buf, uint64(k))
+	case float32:
+		return hashFloat(&buf, float64(k))
+	case float64:
+		return hashFloat(&buf, k)
+	case bool:
+		if k {
+			return hashUint64(&buf, 1)
+		}
+		return hashUint64(&buf, 0)
+	default:
+		var h maphash.Hash
+		h.SetSeed(hashSeed)
+		hashValue(&h, reflect.ValueOf(&key).Elem())
+		return h.Sum64()
+	}
+}
+
+// hashValue writes v to h so that values equal by == are written the same way.
+func hashValue(h *maphash.Hash, v reflect.Value) {
+	var buf [8]byte
+	writeUint64 := func(x uint64) {
+		binary.LittleEndian.PutUint64(buf[:], x)
+		h.Write(buf[:])
+	}
+	writeFloat := func(f float64) {
+		// Positive and negative zeros are equal.
+		if f == 0 {
+			f = 0
+		}
+		writeUint64(math.Float64bits(f))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		h.WriteString(v.String())
+		// Separates strings of adjacent fields, so "a", "bc" differs from "ab", "c".
+		writeUint64(uint64(v.Len()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint64(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeFloat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		writeFloat(real(v.Complex()))
+		writeFloat(imag(v.Complex()))
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint64(1)
+		} else {
+			writeUint64(0)
+		}
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		writeUint64(uint64(v.Pointer()))
+	case reflect.Array:
+		for i := range v.Len() {
+			hashValue(h, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			hashValue(h, v.Field(i))
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			writeUint64(0)
+			return
+		}
+		elem := v.Elem()
+		h.WriteString(elem.Type().String())
+		hashValue(h, elem)
+	default:
+		// Other kinds aren't comparable, so they can only be in interfaces,
+		// where == panics on them anyway.
+		panic("functional: unhashable key type " + v.Type().String())
+	}
+}
+
+func hashUint64(buf *[8]byte, v uint64) uint64 {
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return maphash.Bytes(hashSeed, buf[:])
+}
+
+func hashFloat(buf *[8]byte, v float64) uint64 {
+	// Positive and negative zeros are equal keys.
+	if v == 0 {
+		v = 0
+	}
+	return hashUint64(buf, math.Float64bits(v))
+}
@@ -0,0 +1,163 @@
+package functional
+
+import (
+	"maps"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashMap(t *testing.T) {
+	var m HashMap[string, int]
+	m1 := m.Set("a", 1).Set("b", 2)
+	m2 := m1.Set("a", 10).Delete("b").Set("c", 3)
+
+	require.Zero(t, m.Len())
+	require.Equal(t, map[string]int{"a": 1, "b": 2}, maps.Collect(m1.All()))
+	require.Equal(t, map[string]int{"a": 10, "c": 3}, maps.Collect(m2.All()))
+
+	v, ok := m2.Get("a")
+	require.True(t, ok)
+	require.Equal(t, 10, v)
+	_, ok = m2.Get("b")
+	require.False(t, ok)
+	require.True(t, m1.Contains("b"))
+
+	require.Equal(t, m2, m2.Delete("missing"))
+	require.Zero(t, m2.Delete("a").Delete("c").Len())
+}
+
+func TestHashMapKeys(t *testing.T) {
+	type point struct{ x, y int }
+
+	for _, tc := range []struct {
+		name string
+		m    HashMap[any, int]
+		keys []any
+	}{
+		{name: "basic", keys: []any{1, int8(1), uint(1), "1", 1.5, true, false, math.Copysign(0, -1)}},
+		{name: "composite", keys: []any{point{1, 2}, point{2, 1}, [2]int{1, 2}, [2]string{"a", "bc"}, [2]string{"ab", "c"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := tc.m
+			for i, k := range tc.keys {
+				m = m.Set(k, i)
+			}
+			require.Equal(t, len(tc.keys), m.Len())
+			for i, k := range tc.keys {
+				v, ok := m.Get(k)
+				require.True(t, ok)
+				require.Equal(t, i, v)
+			}
+		})
+	}
+
+	negZero := math.Copysign(0, -1)
+	floats := HashMap[float64, string]{}.Set(0.0, "zero")
+	v, _ := floats.Get(negZero)
+	require.Equal(t, "zero", v)
+
+	// Zeros of different signs are equal in composite keys too.
+	type measure struct {
+		name  string
+		value float64
+	}
+	measures := HashMap[measure, int]{}.Set(measure{"t", 0}, 1)
+	v2, ok := measures.Get(measure{"t", negZero})
+	require.True(t, ok)
+	require.Equal(t, 1, v2)
+	arrays := HashMap[[2]float64, int]{}.Set([2]float64{negZero, 1}, 1)
+	require.True(t, arrays.Contains([2]float64{0, 1}))
+
+	// Pointers are keys by address, whatever they point to.
+	p, q := &point{1, 2}, &point{1, 2}
+	pointers := HashMap[*point, string]{}.Set(p, "p").Set(q, "q")
+	p.x = 100
+	v3, ok := pointers.Get(p)
+	require.True(t, ok)
+	require.Equal(t, "p", v3)
+	require.Equal(t, 2, pointers.Len())
+
+	// Interfaces are compared by dynamic type and value.
+	type wrapped struct{ v any }
+	wraps := HashMap[wrapped, int]{}.Set(wrapped{1}, 1).Set(wrapped{int64(1)}, 2).Set(wrapped{p}, 3).Set(wrapped{nil}, 4)
+	require.Equal(t, 4, wraps.Len())
+	v4, _ := wraps.Get(wrapped{p})
+	require.Equal(t, 3, v4)
+	v4, _ = wraps.Get(wrapped{int64(1)})
+	require.Equal(t, 2, v4)
+}
+
+func TestHashMapRandomOps(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		m    HashMap[int, int]
+	}{
+		{name: "default hash"},
+		// Few distinct hashes put most keys in collision nodes.
+		{name: "collisions", m: NewHashMapFunc[int, int](func(k int) uint64 { return uint64(k % 7) })},
+		// Hashes differing only in the last bits build the deepest tries.
+		{name: "deep", m: NewHashMapFunc[int, int](func(k int) uint64 { return uint64(k%5) << 60 })},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := rand.New(rand.NewPCG(3, 4))
+			m, expected := tc.m, make(map[int]int)
+			versions, models := []HashMap[int, int]{}, []map[int]int{}
+			for i := range 10000 {
+				k := r.IntN(500)
+				if r.IntN(3) == 0 {
+					m = m.Delete(k)
+					delete(expected, k)
+				} else {
+					m = m.Set(k, i)
+					expected[k] = i
+				}
+				require.Equal(t, len(expected), m.Len())
+				if i%1000 == 0 {
+					versions = append(versions, m)
+					models = append(models, maps.Clone(expected))
+				}
+			}
+
+			require.Equal(t, expected, maps.Collect(m.All()))
+			for i, version := range versions {
+				require.Equal(t, models[i], maps.Collect(version.All()))
+			}
+			for k := range 500 {
+				_, ok := expected[k]
+				require.Equal(t, ok, m.Contains(k))
+			}
+		})
+	}
+}
+
+func TestTransientHashMap(t *testing.T) {
+	base := CollectHashMap(maps.All(map[int]string{1: "a", 2: "b"}))
+
+	tr := base.Transient()
+	for i := range 1000 {
+		tr.Set(i, "x")
+	}
+	tr.Delete(2)
+	tr.Delete(5000)
+	require.Equal(t, 999, tr.Len())
+	v, ok := tr.Get(1)
+	require.True(t, ok)
+	require.Equal(t, "x", v)
+
+	m := tr.Persistent()
+	require.Panics(t, func() { tr.Set(0, "") })
+
+	other := m.Transient()
+	other.Set(1, "y")
+	other.Delete(3)
+
+	require.Equal(t, 999, m.Len())
+	require.False(t, m.Contains(2))
+	v, _ = m.Get(1)
+	require.Equal(t, "x", v)
+	require.True(t, m.Contains(3))
+	require.Equal(t, map[int]string{1: "a", 2: "b"}, maps.Collect(base.All()))
+}
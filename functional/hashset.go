@@ -0,0 +1,100 @@
+package functional
+
+import "iter"
+
+// HashSet is an immutable set. Methods that change the set return a new one,
+// sharing most of its structure with the original.
+//
+// It is a HashMap without values, see HashMap for details of hashing.
+// The zero HashSet is empty and ready to use.
+type HashSet[T comparable] struct {
+	m HashMap[T, struct{}]
+}
+
+// NewHashSetFunc creates an empty HashSet that hashes elements with the function hash.
+// Equal elements must have equal hashes.
+func NewHashSetFunc[T comparable](hash func(T) uint64) HashSet[T] {
+	return HashSet[T]{m: NewHashMapFunc[T, struct{}](hash)}
+}
+
+// HashSetOf creates a new HashSet with the values.
+func HashSetOf[T comparable](values ...T) HashSet[T] {
+	t := HashSet[T]{}.Transient()
+	for _, v := range values {
+		t.Add(v)
+	}
+	return t.Persistent()
+}
+
+// CollectHashSet creates a new HashSet with the values of the sequence.
+func CollectHashSet[T comparable](values iter.Seq[T]) HashSet[T] {
+	t := HashSet[T]{}.Transient()
+	for v := range values {
+		t.Add(v)
+	}
+	return t.Persistent()
+}
+
+// Len returns the number of elements in the set.
+func (s HashSet[T]) Len() int {
+	return s.m.Len()
+}
+
+// Contains reports whether the value is in the set.
+func (s HashSet[T]) Contains(value T) bool {
+	return s.m.Contains(value)
+}
+
+// Add returns a new set with the value.
+func (s HashSet[T]) Add(value T) HashSet[T] {
+	return HashSet[T]{m: s.m.Set(value, struct{}{})}
+}
+
+// Delete returns a new set without the value.
+func (s HashSet[T]) Delete(value T) HashSet[T] {
+	return HashSet[T]{m: s.m.Delete(value)}
+}
+
+// All returns an iterator over elements of the set, which can be used
+// as a source of a Stream.
+func (s HashSet[T]) All() iter.Seq[T] {
+	return s.m.Keys()
+}
+
+// Transient returns a TransientHashSet with the elements of the set.
+// The set itself is not affected by changes of the transient.
+func (s HashSet[T]) Transient() *TransientHashSet[T] {
+	return &TransientHashSet[T]{m: s.m.Transient()}
+}
+
+// TransientHashSet is a mutable builder of a HashSet, see TransientHashMap.
+type TransientHashSet[T comparable] struct {
+	m *TransientHashMap[T, struct{}]
+}
+
+// Len returns the number of elements in the transient.
+func (t *TransientHashSet[T]) Len() int {
+	return t.m.Len()
+}
+
+// Contains reports whether the value is in the transient.
+func (t *TransientHashSet[T]) Contains(value T) bool {
+	_, ok := t.m.Get(value)
+	return ok
+}
+
+// Add adds the value.
+func (t *TransientHashSet[T]) Add(value T) {
+	t.m.Set(value, struct{}{})
+}
+
+// Delete removes the value.
+func (t *TransientHashSet[T]) Delete(value T) {
+	t.m.Delete(value)
+}
+
+// Persistent returns a HashSet with the elements of the transient
+// and invalidates the transient.
+func (t *TransientHashSet[T]) Persistent() HashSet[T] {
+	return HashSet[T]{m: t.m.Persistent()}
+}
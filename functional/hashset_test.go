@@ -0,0 +1,35 @@
+package functional
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashSet(t *testing.T) {
+	s1 := HashSetOf("a", "b", "a")
+	s2 := s1.Add("c").Delete("a")
+
+	require.Equal(t, 2, s1.Len())
+	require.ElementsMatch(t, []string{"a", "b"}, slices.Collect(s1.All()))
+	require.ElementsMatch(t, []string{"b", "c"}, slices.Collect(s2.All()))
+	require.True(t, s1.Contains("a"))
+	require.False(t, s2.Contains("a"))
+
+	evens := CollectHashSet(From(Range(0, 100, 1).Iterate()).Filter(func(x int) bool { return x%2 == 0 }).Iterate())
+	require.Equal(t, 50, evens.Len())
+	require.Equal(t, 2450, Sum(NewStream(evens.All())))
+
+	tr := evens.Transient()
+	tr.Add(1)
+	tr.Delete(0)
+	require.True(t, tr.Contains(1))
+	odd := tr.Persistent()
+	require.Equal(t, 50, odd.Len())
+	require.True(t, evens.Contains(0))
+	require.False(t, evens.Contains(1))
+
+	byLength := NewHashSetFunc(func(s string) uint64 { return uint64(len(s)) }).Add("ab").Add("cd").Add("e")
+	require.Equal(t, 3, byLength.Len())
+}
@@ -0,0 +1,241 @@
+package functional
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// Pair holds two values, possibly of different types.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// FlatMap applies the function f to each element in the stream and returns a new stream
+// with the elements of all resulting sequences.
+func FlatMap[T, U any](s *Stream[T], f func(T) iter.Seq[U]) *Stream[U] {
+	return derive(s, "FlatMap", func(yield func(U) bool) {
+		for v := range s.seq {
+			for u := range f(v) {
+				if !yield(u) {
+					return
+				}
+			}
+		}
+	})
+}
+
+// Zip returns a new stream of pairs of elements at the same position in a and b.
+// The stream ends as soon as either of them does.
+func Zip[T, U any](a *Stream[T], b *Stream[U]) *Stream[Pair[T, U]] {
+	return derive(a, "Zip", func(yield func(Pair[T, U]) bool) {
+		next, stop := iter.Pull(b.seq)
+		defer stop()
+
+		for v := range a.seq {
+			u, ok := next()
+			if !ok || !yield(Pair[T, U]{v, u}) {
+				return
+			}
+		}
+	})
+}
+
+// Enumerate returns a new stream of pairs of element indexes and elements.
+func Enumerate[T any](s *Stream[T]) *Stream[Pair[int, T]] {
+	return derive(s, "Enumerate", func(yield func(Pair[int, T]) bool) {
+		i := 0
+		for v := range s.seq {
+			if !yield(Pair[int, T]{i, v}) {
+				return
+			}
+			i++
+		}
+	})
+}
+
+// Chunk returns a new stream of consecutive slices of n elements.
+// The last slice may contain fewer than n elements.
+//
+// Panics if n is less than 1.
+func Chunk[T any](s *Stream[T], n int) *Stream[[]T] {
+	if n < 1 {
+		panic("functional: chunk size must be positive")
+	}
+	return derive(s, "Chunk", func(yield func([]T) bool) {
+		chunk := make([]T, 0, n)
+		for v := range s.seq {
+			chunk = append(chunk, v)
+			if len(chunk) < n {
+				continue
+			}
+			if !yield(chunk) {
+				return
+			}
+			chunk = make([]T, 0, n)
+		}
+		if len(chunk) > 0 {
+			yield(chunk)
+		}
+	})
+}
+
+// Window returns a new stream of sliding windows of n consecutive elements,
+// advancing by one element at a time. Streams shorter than n produce no windows.
+//
+// Every window is a separate slice, so it is safe to retain it.
+//
+// Panics if n is less than 1.
+func Window[T any](s *Stream[T], n int) *Stream[[]T] {
+	if n < 1 {
+		panic("functional: window size must be positive")
+	}
+	return derive(s, "Window", func(yield func([]T) bool) {
+		window := make([]T, 0, n)
+		for v := range s.seq {
+			if len(window) == n {
+				window = window[1:]
+			}
+			window = append(window, v)
+			if len(window) == n && !yield(slices.Clone(window)) {
+				return
+			}
+		}
+	})
+}
+
+// Scan returns a new stream of successive results of folding the stream
+// with the function f, starting from seed. The seed itself is not emitted.
+func Scan[T, A any](s *Stream[T], seed A, f func(A, T) A) *Stream[A] {
+	return derive(s, "Scan", func(yield func(A) bool) {
+		acc := seed
+		for v := range s.seq {
+			acc = f(acc, v)
+			if !yield(acc) {
+				return
+			}
+		}
+	})
+}
+
+// Distinct returns a new stream without repeated elements, keeping the first occurrence of each.
+//
+// All seen elements are kept in memory.
+func Distinct[T comparable](s *Stream[T]) *Stream[T] {
+	return derive(s, "Distinct", func(yield func(T) bool) {
+		seen := make(map[T]struct{})
+		for v := range s.seq {
+			if _, ok := seen[v]; ok {
+				continue
+			}
+			seen[v] = struct{}{}
+			if !yield(v) {
+				return
+			}
+		}
+	})
+}
+
+// Sorted returns a new stream with the elements in ascending order.
+//
+// The whole stream is read into memory before the first element is produced,
+// so it must be finite.
+func Sorted[T cmp.Ordered](s *Stream[T]) *Stream[T] {
+	return s.SortedFunc(cmp.Compare[T])
+}
+
+// SortedFunc returns a new stream with the elements sorted by the comparison function cmp.
+// The sort is stable.
+//
+// The whole stream is read into memory before the first element is produced,
+// so it must be finite.
+func (s *Stream[T]) SortedFunc(cmp func(a, b T) int) *Stream[T] {
+	return derive(s, "SortedFunc", func(yield func(T) bool) {
+		values := slices.Collect(s.seq)
+		slices.SortStableFunc(values, cmp)
+		for _, v := range values {
+			if !yield(v) {
+				return
+			}
+		}
+	})
+}
+
+// TakeWhile returns a new stream with the leading elements that satisfy the predicate.
+func (s *Stream[T]) TakeWhile(predicate func(T) bool) *Stream[T] {
+	return derive(s, "TakeWhile", func(yield func(T) bool) {
+		for v := range s.seq {
+			if !predicate(v) || !yield(v) {
+				return
+			}
+		}
+	})
+}
+
+// DropWhile returns a new stream without the leading elements that satisfy the predicate.
+func (s *Stream[T]) DropWhile(predicate func(T) bool) *Stream[T] {
+	return derive(s, "DropWhile", func(yield func(T) bool) {
+		dropping := true
+		for v := range s.seq {
+			if dropping && predicate(v) {
+				continue
+			}
+			dropping = false
+			if !yield(v) {
+				return
+			}
+		}
+	})
+}
+
+// Concat returns a new stream with the elements of the stream followed by the elements of others.
+func (s *Stream[T]) Concat(others ...*Stream[T]) *Stream[T] {
+	return derive(s, "Concat", func(yield func(T) bool) {
+		for v := range s.seq {
+			if !yield(v) {
+				return
+			}
+		}
+		for _, other := range others {
+			for v := range other.seq {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	})
+}
+
+// Interleave returns a new stream alternating the elements of the stream and other,
+// starting with the stream. When one of them ends, the rest of the other follows.
+func (s *Stream[T]) Interleave(other *Stream[T]) *Stream[T] {
+	return derive(s, "Interleave", func(yield func(T) bool) {
+		next, stop := iter.Pull(other.seq)
+		defer stop()
+
+		otherDone := false
+		for v := range s.seq {
+			if !yield(v) {
+				return
+			}
+			if otherDone {
+				continue
+			}
+			u, ok := next()
+			if !ok {
+				otherDone = true
+				continue
+			}
+			if !yield(u) {
+				return
+			}
+		}
+		for !otherDone {
+			u, ok := next()
+			if !ok || !yield(u) {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package functional
+
+import (
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func naturals() *Stream[int] {
+	return From(func(yield func(int) bool) {
+		for i := 1; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	})
+}
+
+func TestFlatMap(t *testing.T) {
+	stream := From(slices.Values([]string{"a b", "", "c"}))
+	words := FlatMap(stream, func(s string) iter.Seq[string] {
+		return slices.Values(strings.Fields(s))
+	})
+	require.Equal(t, []string{"a", "b", "c"}, slices.Collect(words.Iterate()))
+
+	repeated := FlatMap(naturals(), func(x int) iter.Seq[int] {
+		return slices.Values([]int{x, x})
+	}).Take(5)
+	require.Equal(t, []int{1, 1, 2, 2, 3}, slices.Collect(repeated.Iterate()))
+}
+
+func TestZip(t *testing.T) {
+	letters := From(slices.Values([]string{"a", "b", "c"}))
+	zipped := Zip(naturals(), letters)
+	require.Equal(t, []Pair[int, string]{{1, "a"}, {2, "b"}, {3, "c"}}, slices.Collect(zipped.Iterate()))
+
+	zipped = Zip(naturals(), letters).Take(2)
+	require.Equal(t, []Pair[int, string]{{1, "a"}, {2, "b"}}, slices.Collect(zipped.Iterate()))
+}
+
+func TestEnumerate(t *testing.T) {
+	stream := Enumerate(From(slices.Values([]string{"x", "y"})))
+	require.Equal(t, []Pair[int, string]{{0, "x"}, {1, "y"}}, slices.Collect(stream.Iterate()))
+}
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []int
+		n        int
+		expected [][]int
+	}{
+		{
+			name:     "even",
+			input:    []int{1, 2, 3, 4},
+			n:        2,
+			expected: [][]int{{1, 2}, {3, 4}},
+		},
+		{
+			name:     "remainder",
+			input:    []int{1, 2, 3, 4, 5},
+			n:        2,
+			expected: [][]int{{1, 2}, {3, 4}, {5}},
+		},
+		{
+			name:     "empty",
+			input:    []int{},
+			n:        3,
+			expected: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			chunks := Chunk(NewStream(slices.Values(tc.input)), tc.n)
+			require.Equal(t, tc.expected, slices.Collect(chunks.Iterate()))
+		})
+	}
+
+	require.Panics(t, func() { Chunk(naturals(), 0) })
+	require.Equal(t, [][]int{{1, 2, 3}, {4, 5, 6}}, slices.Collect(Chunk(naturals(), 3).Take(2).Iterate()))
+}
+
+func TestWindow(t *testing.T) {
+	windows := slices.Collect(Window(NewStream(slices.Values([]int{1, 2, 3, 4})), 3).Iterate())
+	require.Equal(t, [][]int{{1, 2, 3}, {2, 3, 4}}, windows)
+
+	windows = slices.Collect(Window(NewStream(slices.Values([]int{1, 2})), 3).Iterate())
+	require.Empty(t, windows)
+
+	windows = slices.Collect(Window(naturals(), 2).Take(3).Iterate())
+	require.Equal(t, [][]int{{1, 2}, {2, 3}, {3, 4}}, windows)
+}
+
+func TestScan(t *testing.T) {
+	sums := Scan(naturals(), 0, func(acc, x int) int { return acc + x }).Take(4)
+	require.Equal(t, []int{1, 3, 6, 10}, slices.Collect(sums.Iterate()))
+
+	prefixes := Scan(From(slices.Values([]string{"a", "b"})), ">", func(acc, s string) string { return acc + s })
+	require.Equal(t, []string{">a", ">ab"}, slices.Collect(prefixes.Iterate()))
+}
+
+func TestDistinct(t *testing.T) {
+	stream := Distinct(NewStream(slices.Values([]int{3, 1, 3, 2, 1})))
+	require.Equal(t, []int{3, 1, 2}, slices.Collect(stream.Iterate()))
+
+	mod := Distinct(naturals().Map(func(x int) int { return x % 3 })).Take(3)
+	require.Equal(t, []int{1, 2, 0}, slices.Collect(mod.Iterate()))
+}
+
+func TestSorted(t *testing.T) {
+	stream := Sorted(NewStream(slices.Values([]int{3, 1, 2})))
+	require.Equal(t, []int{1, 2, 3}, slices.Collect(stream.Iterate()))
+
+	words := From(slices.Values([]string{"bb", "a", "cc", "d"})).SortedFunc(func(a, b string) int {
+		return len(a) - len(b)
+	})
+	require.Equal(t, []string{"a", "d", "bb", "cc"}, slices.Collect(words.Iterate()))
+}
+
+func TestTakeWhileDropWhile(t *testing.T) {
+	small := naturals().TakeWhile(func(x int) bool { return x < 4 })
+	require.Equal(t, []int{1, 2, 3}, slices.Collect(small.Iterate()))
+
+	large := naturals().DropWhile(func(x int) bool { return x < 4 }).Take(3)
+	require.Equal(t, []int{4, 5, 6}, slices.Collect(large.Iterate()))
+
+	// Only leading elements are dropped.
+	stream := NewStream(slices.Values([]int{1, 5, 1})).DropWhile(func(x int) bool { return x < 4 })
+	require.Equal(t, []int{5, 1}, slices.Collect(stream.Iterate()))
+}
+
+func TestConcat(t *testing.T) {
+	stream := NewStream(slices.Values([]int{1, 2})).Concat(
+		NewStream(slices.Values([]int{3})),
+		naturals(),
+	).Take(5)
+	require.Equal(t, []int{1, 2, 3, 1, 2}, slices.Collect(stream.Iterate()))
+}
+
+func TestInterleave(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     []int
+		expected []int
+	}{
+		{
+			name:     "same_length",
+			a:        []int{1, 3},
+			b:        []int{2, 4},
+			expected: []int{1, 2, 3, 4},
+		},
+		{
+			name:     "longer_first",
+			a:        []int{1, 3, 5, 6},
+			b:        []int{2, 4},
+			expected: []int{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:     "longer_second",
+			a:        []int{1},
+			b:        []int{2, 3, 4},
+			expected: []int{1, 2, 3, 4},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stream := NewStream(slices.Values(tc.a)).Interleave(NewStream(slices.Values(tc.b)))
+			require.Equal(t, tc.expected, slices.Collect(stream.Iterate()))
+		})
+	}
+
+	negatives := naturals().Map(func(x int) int { return -x })
+	stream := naturals().Interleave(negatives).Take(5)
+	require.Equal(t, []int{1, -1, 2, -2, 3}, slices.Collect(stream.Iterate()))
+}
@@ -0,0 +1,205 @@
+package functional
+
+import (
+	"iter"
+	"sync"
+)
+
+// Parallel returns a new stream whose Map and Filter operations, including the ones
+// applied to streams derived from it, run on the given number of goroutines.
+//
+// Elements are emitted in the input order, which may require buffering results
+// of fast calls until slower ones before them finish. Use Unordered to emit them
+// as soon as they are ready. At most a few elements per worker are in flight
+// at any moment, so Parallel is safe on infinite streams.
+//
+// The functions passed to Map and Filter must be safe for concurrent use.
+func (s *Stream[T]) Parallel(workers int) *Stream[T] {
+	p := s.clone()
+	p.workers = workers
+	p.unordered = false
+	return p
+}
+
+// Unordered returns a new stream whose parallel Map and Filter operations
+// emit elements as soon as they are ready, in no particular order.
+func (s *Stream[T]) Unordered() *Stream[T] {
+	p := s.clone()
+	p.unordered = true
+	return p
+}
+
+// Sequential returns a new stream whose Map and Filter operations
+// run on the calling goroutine.
+func (s *Stream[T]) Sequential() *Stream[T] {
+	p := s.clone()
+	p.workers = 0
+	return p
+}
+
+type parallelTask[T any] struct {
+	index int
+	value T
+}
+
+type parallelResult[U any] struct {
+	index int
+	value U
+	keep  bool
+}
+
+// parallel applies f to the elements of s on s.workers goroutines, emitting
+// results for which f returned true.
+//
+// All goroutines are stopped before the returned sequence finishes, even if
+// the consumer stops early, except the one pulling elements from s if it is
+// blocked in s waiting for the next one, like on an idle channel. Waiting for
+// it could take forever, so it is left to exit once s yields or ends.
+func parallel[T, U any](s *Stream[T], f func(T) (U, bool)) iter.Seq[U] {
+	workers, ordered := s.workers, !s.unordered
+	return func(yield func(U) bool) {
+		var (
+			done    = make(chan struct{})
+			tasks   = make(chan parallelTask[T])
+			results = make(chan parallelResult[U], workers)
+			// inFlight bounds the number of elements taken from s but not
+			// yet emitted, which keeps the reorder buffer small.
+			inFlight = make(chan struct{}, 2*workers)
+			// pulled is closed when the producer exits.
+			pulled = make(chan struct{})
+			gate   pullGate
+		)
+
+		go func() {
+			defer close(pulled)
+			defer close(tasks)
+			defer gate.leave()
+
+			if !gate.enter() {
+				return
+			}
+			index := 0
+			for v := range s.seq {
+				gate.leave()
+
+				select {
+				case inFlight <- struct{}{}:
+				case <-done:
+					return
+				}
+				select {
+				case tasks <- parallelTask[T]{index, v}:
+				case <-done:
+					return
+				}
+				index++
+				if !gate.enter() {
+					return
+				}
+			}
+		}()
+
+		var workersWg sync.WaitGroup
+		workersWg.Add(workers)
+		for range workers {
+			go func() {
+				defer workersWg.Done()
+				for {
+					var task parallelTask[T]
+					select {
+					case t, ok := <-tasks:
+						if !ok {
+							return
+						}
+						task = t
+					case <-done:
+						return
+					}
+					u, keep := f(task.value)
+					select {
+					case results <- parallelResult[U]{task.index, u, keep}:
+					case <-done:
+						return
+					}
+				}
+			}()
+		}
+		go func() {
+			workersWg.Wait()
+			close(results)
+		}()
+
+		defer func() {
+			parked := gate.stop()
+			close(done)
+			for range results {
+			}
+			if !parked {
+				<-pulled
+			}
+		}()
+
+		if !ordered {
+			for r := range results {
+				<-inFlight
+				if r.keep && !yield(r.value) {
+					return
+				}
+			}
+			return
+		}
+
+		pending := make(map[int]parallelResult[U])
+		next := 0
+		for r := range results {
+			pending[r.index] = r
+			for {
+				r, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+				<-inFlight
+				if r.keep && !yield(r.value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// pullGate tracks whether a goroutine pulling elements from a sequence is blocked
+// inside it, so that a consumer stopping early doesn't wait for it forever.
+type pullGate struct {
+	mu      sync.Mutex
+	pulling bool
+	stopped bool
+}
+
+// enter is called before waiting in the sequence for the next element.
+// It returns false if the consumer has stopped, and then the sequence
+// must not be entered again.
+func (g *pullGate) enter() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pulling = !g.stopped
+	return g.pulling
+}
+
+// leave is called once the sequence has yielded an element or ended.
+func (g *pullGate) leave() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pulling = false
+}
+
+// stop marks the consumer as stopped and reports whether the goroutine
+// is blocked in the sequence. If it isn't, it will notice the stop
+// without entering the sequence again.
+func (g *pullGate) stop() (parked bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stopped = true
+	return g.pulling
+}
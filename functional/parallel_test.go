@@ -0,0 +1,163 @@
+package functional
+
+import (
+	"context"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelOrdered(t *testing.T) {
+	input := make([]int, 1000)
+	for i := range input {
+		input[i] = i
+	}
+
+	stream := NewStream(slices.Values(input)).
+		Parallel(8).
+		Map(func(x int) int {
+			if x%7 == 0 {
+				time.Sleep(time.Microsecond * 50)
+			}
+			return x * 2
+		}).
+		Filter(func(x int) bool { return x%3 == 0 })
+
+	expected := slices.Collect(NewStream(slices.Values(input)).
+		Map(func(x int) int { return x * 2 }).
+		Filter(func(x int) bool { return x%3 == 0 }).
+		Iterate())
+	require.Equal(t, expected, slices.Collect(stream.Iterate()))
+}
+
+func TestParallelUnordered(t *testing.T) {
+	input := make([]int, 500)
+	for i := range input {
+		input[i] = i
+	}
+
+	stream := Map(NewStream(slices.Values(input)).Parallel(4).Unordered(), func(x int) int {
+		return x + 1
+	})
+	result := slices.Collect(stream.Iterate())
+	slices.Sort(result)
+
+	expected := make([]int, len(input))
+	for i := range expected {
+		expected[i] = i + 1
+	}
+	require.Equal(t, expected, result)
+}
+
+func TestParallelRunsConcurrently(t *testing.T) {
+	const workers = 4
+
+	var (
+		running atomic.Int64
+		peak    atomic.Int64
+		barrier sync.WaitGroup
+	)
+	barrier.Add(workers)
+
+	stream := NewStream(slices.Values([]int{1, 2, 3, 4, 5, 6, 7, 8})).Parallel(workers).Map(func(x int) int {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		if x <= workers {
+			// The first elements block until all workers have started.
+			barrier.Done()
+			barrier.Wait()
+		}
+		running.Add(-1)
+		return x
+	})
+
+	require.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, slices.Collect(stream.Iterate()))
+	require.Equal(t, int64(workers), peak.Load())
+}
+
+func TestParallelStopsEarly(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	var calls atomic.Int64
+	for _, unordered := range []bool{false, true} {
+		stream := naturals().Parallel(4)
+		if unordered {
+			stream = stream.Unordered()
+		}
+		result := stream.
+			Map(func(x int) int {
+				calls.Add(1)
+				return x * 2
+			}).
+			Take(5).
+			FoldLeft(func(acc, x int) int { return acc + x })
+		if !unordered {
+			require.Equal(t, 30, result)
+		}
+	}
+
+	// Only a bounded number of elements were taken from the infinite stream.
+	require.Less(t, calls.Load(), int64(100))
+
+	// All workers have exited by the time the terminal operation returns.
+	require.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
+
+func TestParallelInfiniteSequence(t *testing.T) {
+	result := naturals().
+		Parallel(3).
+		Map(func(x int) int { return x * 2 }).
+		Drop(10).
+		Filter(func(x int) bool { return x%3 == 0 }).
+		Take(5).
+		FoldLeft(func(acc, x int) int { return acc + x })
+
+	require.Equal(t, 180, result)
+}
+
+func TestSequential(t *testing.T) {
+	var concurrent, maxConcurrent atomic.Int64
+	stream := NewStream(slices.Values([]int{1, 2, 3, 4})).Parallel(4).Sequential().Map(func(x int) int {
+		maxConcurrent.Store(max(maxConcurrent.Load(), concurrent.Add(1)))
+		concurrent.Add(-1)
+		return x
+	})
+
+	require.Equal(t, []int{1, 2, 3, 4}, slices.Collect(stream.Iterate()))
+	require.Equal(t, int64(1), maxConcurrent.Load())
+}
+
+func TestParallelStopsOnBlockedSource(t *testing.T) {
+	for _, unordered := range []bool{false, true} {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := make(chan int, 1)
+		ch <- 1
+
+		stream := FromChannel(ctx, ch).Parallel(2)
+		if unordered {
+			stream = stream.Unordered()
+		}
+		done := make(chan []int)
+		go func() {
+			done <- Map(stream, func(x int) int { return x * 10 }).Take(1).Collect()
+		}()
+
+		select {
+		case result := <-done:
+			require.Equal(t, []int{10}, result)
+		case <-time.After(time.Second):
+			t.Fatal("Take did not stop the parallel stream while its source was blocked")
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,82 @@
+package functional
+
+import "iter"
+
+// Pipeline describes a chain of stream operations from elements of type T
+// to elements of type U, independent of any source.
+//
+// A Pipeline is immutable: methods return new pipelines, and every Apply
+// builds a fresh chain of streams, so one pipeline can be applied to many
+// sources, including concurrently, as long as the functions passed
+// to its operations are safe for concurrent use.
+type Pipeline[T, U any] struct {
+	build func(*Stream[T]) *Stream[U]
+}
+
+// NewPipeline creates a Pipeline that passes elements through unchanged.
+func NewPipeline[T any]() Pipeline[T, T] {
+	return Pipeline[T, T]{build: func(s *Stream[T]) *Stream[T] { return s }}
+}
+
+// Pipe creates a Pipeline from the function f building a stream from another one,
+// which allows using operations that change the element type, like Map or Chunk.
+func Pipe[T, U any](f func(*Stream[T]) *Stream[U]) Pipeline[T, U] {
+	return Pipeline[T, U]{build: f}
+}
+
+// Compose returns a Pipeline that applies p and then q.
+func Compose[T, U, V any](p Pipeline[T, U], q Pipeline[U, V]) Pipeline[T, V] {
+	return Pipeline[T, V]{build: func(s *Stream[T]) *Stream[V] {
+		return q.Stream(p.Stream(s))
+	}}
+}
+
+// Then returns a Pipeline that applies p and then q.
+//
+// Use Compose if q changes the element type.
+func (p Pipeline[T, U]) Then(q Pipeline[U, U]) Pipeline[T, U] {
+	return Compose(p, q)
+}
+
+// Apply returns a stream with the elements of values transformed by the pipeline.
+func (p Pipeline[T, U]) Apply(values iter.Seq[T]) *Stream[U] {
+	return p.Stream(From(values))
+}
+
+// Stream returns a stream with the elements of s transformed by the pipeline.
+// Settings of s, like Parallel, apply to the operations of the pipeline.
+func (p Pipeline[T, U]) Stream(s *Stream[T]) *Stream[U] {
+	if p.build == nil {
+		panic("functional: use of uninitialized Pipeline")
+	}
+	return p.build(s)
+}
+
+// Map returns a Pipeline that also applies the function f to each element.
+func (p Pipeline[T, U]) Map(f func(U) U) Pipeline[T, U] {
+	return p.then(func(s *Stream[U]) *Stream[U] { return s.Map(f) })
+}
+
+// Filter returns a Pipeline that also keeps only the elements that satisfy the predicate.
+func (p Pipeline[T, U]) Filter(predicate func(U) bool) Pipeline[T, U] {
+	return p.then(func(s *Stream[U]) *Stream[U] { return s.Filter(predicate) })
+}
+
+// Take returns a Pipeline that also keeps at most the first n elements.
+func (p Pipeline[T, U]) Take(n int) Pipeline[T, U] {
+	return p.then(func(s *Stream[U]) *Stream[U] { return s.Take(n) })
+}
+
+// Drop returns a Pipeline that also removes the first n elements.
+func (p Pipeline[T, U]) Drop(n int) Pipeline[T, U] {
+	return p.then(func(s *Stream[U]) *Stream[U] { return s.Drop(n) })
+}
+
+// Peek returns a Pipeline that also calls the function f on each element.
+func (p Pipeline[T, U]) Peek(f func(U)) Pipeline[T, U] {
+	return p.then(func(s *Stream[U]) *Stream[U] { return s.Peek(f) })
+}
+
+func (p Pipeline[T, U]) then(f func(*Stream[U]) *Stream[U]) Pipeline[T, U] {
+	return p.Then(Pipe(f))
+}
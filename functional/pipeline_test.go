@@ -0,0 +1,72 @@
+package functional
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeline(t *testing.T) {
+	odd := NewPipeline[int]().Filter(func(x int) bool { return x%2 != 0 })
+	tail := NewPipeline[int]().Drop(1).Take(2).Map(func(x int) int { return x + 1 })
+	p := NewPipeline[int]().Map(func(x int) int { return x * 3 }).Then(odd).Then(tail)
+
+	for _, tc := range []struct {
+		name     string
+		input    []int
+		expected []int
+	}{
+		{name: "combined", input: []int{1, 2, 3, 4, 5, 6, 7}, expected: []int{10, 16}},
+		{name: "short", input: []int{1, 3}, expected: []int{10}},
+		{name: "empty", input: nil, expected: nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Applying twice makes sure no state is shared between runs.
+			require.Equal(t, tc.expected, slices.Collect(p.Apply(slices.Values(tc.input)).Iterate()))
+			require.Equal(t, tc.expected, slices.Collect(p.Apply(slices.Values(tc.input)).Iterate()))
+		})
+	}
+}
+
+func TestPipelineCompose(t *testing.T) {
+	format := Pipe(func(s *Stream[int]) *Stream[string] { return Map(s, strconv.Itoa) })
+	chunks := Pipe(func(s *Stream[string]) *Stream[[]string] { return Chunk(s, 2) })
+	p := Compose(Compose(NewPipeline[int]().Take(3), format), chunks)
+
+	result := p.Apply(slices.Values([]int{1, 2, 3, 4})).Collect()
+	require.Equal(t, [][]string{{"1", "2"}, {"3"}}, result)
+
+	parallel := p.Stream(naturals().Parallel(4))
+	require.Equal(t, [][]string{{"1", "2"}, {"3"}}, parallel.Collect())
+}
+
+func TestPipelineConcurrent(t *testing.T) {
+	p := NewPipeline[int]().
+		Filter(func(x int) bool { return x%3 == 0 }).
+		Map(func(x int) int { return x * x }).
+		Take(3)
+
+	var wg sync.WaitGroup
+	results := make([][]int, 16)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = p.Apply(Range(i, i+100, 1).Iterate()).Collect()
+		}()
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		first := (i + 2) / 3 * 3
+		require.Equal(t, []int{first * first, (first + 3) * (first + 3), (first + 6) * (first + 6)}, result)
+	}
+}
+
+func TestPipelineUninitialized(t *testing.T) {
+	var p Pipeline[int, int]
+	require.Panics(t, func() { p.Apply(slices.Values([]int{1})) })
+}
@@ -0,0 +1,121 @@
+package functional
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"slices"
+)
+
+// FromSlice creates a new Stream with the elements of the slice.
+//
+// Map, Filter, Take and Drop operations fused into the stream index the slice directly.
+func FromSlice[T any](values []T) *Stream[T] {
+	f := &fusion[T]{source: slices.Values(values), slice: values, sliced: true}
+	return &Stream[T]{seq: f.seq, fused: f}
+}
+
+// Range creates a new Stream of integers from start up to, but not including, end,
+// incrementing by step. A negative step counts down.
+//
+// Panics if step is zero.
+func Range(start, end, step int) *Stream[int] {
+	if step == 0 {
+		panic("functional: range step must not be zero")
+	}
+	return From(func(yield func(int) bool) {
+		if (step > 0 && start >= end) || (step < 0 && start <= end) {
+			return
+		}
+		// The distance to end and the stride are unsigned, so that neither
+		// they nor i overflow near the limits of int.
+		left, stride := uint(end-start), uint(step)
+		if step < 0 {
+			left, stride = uint(start-end), uint(-step)
+		}
+		for i := start; yield(i) && left > stride; i += step {
+			left -= stride
+		}
+	})
+}
+
+// Iterate creates a new infinite Stream of seed, f(seed), f(f(seed)) and so on.
+func Iterate[T any](seed T, f func(T) T) *Stream[T] {
+	return From(func(yield func(T) bool) {
+		for v := seed; yield(v); v = f(v) {
+		}
+	})
+}
+
+// Repeat creates a new infinite Stream that repeats the value.
+func Repeat[T any](value T) *Stream[T] {
+	return From(func(yield func(T) bool) {
+		for yield(value) {
+		}
+	})
+}
+
+// Generate creates a new infinite Stream of the values returned by successive calls to f.
+func Generate[T any](f func() T) *Stream[T] {
+	return From(func(yield func(T) bool) {
+		for yield(f()) {
+		}
+	})
+}
+
+// FromChannel creates a new Stream of the values received from the channel.
+// The stream ends when the channel is closed or the context is cancelled.
+//
+// Values received from the channel are consumed, so the stream can be iterated only once.
+func FromChannel[T any](ctx context.Context, ch <-chan T) *Stream[T] {
+	return From(func(yield func(T) bool) {
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok || !yield(v) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// Lines creates a new TryStream of the lines read from r, without line endings.
+// A read error is emitted as the last element.
+//
+// The reader is consumed, so the stream can be iterated only once.
+func Lines(r io.Reader) *TryStream[string] {
+	return TryFrom(func(yield func(string, error) bool) {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if !yield(scanner.Text(), nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield("", err)
+		}
+	})
+}
+
+// ToChannel starts sending the elements in the stream to the returned channel
+// in a new goroutine. The channel is closed after the last element.
+//
+// If the context is cancelled, the stream stops producing elements
+// and the channel is closed.
+func (s *Stream[T]) ToChannel(ctx context.Context) <-chan T {
+	ch := make(chan T)
+	go func() {
+		defer close(ch)
+		for v := range s.seq {
+			select {
+			case ch <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
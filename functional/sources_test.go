@@ -0,0 +1,165 @@
+package functional
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		name             string
+		start, end, step int
+		expected         []int
+	}{
+		{
+			name:     "ascending",
+			start:    0,
+			end:      5,
+			step:     2,
+			expected: []int{0, 2, 4},
+		},
+		{
+			name:     "descending",
+			start:    5,
+			end:      0,
+			step:     -2,
+			expected: []int{5, 3, 1},
+		},
+		{
+			name:     "empty",
+			start:    5,
+			end:      0,
+			step:     1,
+			expected: nil,
+		},
+		{
+			name:     "max_int",
+			start:    math.MaxInt - 1,
+			end:      math.MaxInt,
+			step:     2,
+			expected: []int{math.MaxInt - 1},
+		},
+		{
+			name:     "min_int",
+			start:    math.MinInt + 2,
+			end:      math.MinInt,
+			step:     -3,
+			expected: []int{math.MinInt + 2},
+		},
+		{
+			name:     "whole_int",
+			start:    math.MinInt,
+			end:      math.MaxInt,
+			step:     math.MaxInt,
+			expected: []int{math.MinInt, -1, math.MaxInt - 1},
+		},
+		{
+			name:     "min_step",
+			start:    math.MaxInt,
+			end:      math.MinInt,
+			step:     math.MinInt,
+			expected: []int{math.MaxInt, -1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, Range(tc.start, tc.end, tc.step).Collect())
+		})
+	}
+
+	require.Panics(t, func() { Range(0, 1, 0) })
+}
+
+func TestInfiniteSources(t *testing.T) {
+	powers := Iterate(1, func(x int) int { return x * 2 }).Take(5).Collect()
+	require.Equal(t, []int{1, 2, 4, 8, 16}, powers)
+
+	require.Equal(t, []string{"a", "a", "a"}, Repeat("a").Take(3).Collect())
+
+	n := 0
+	generated := Generate(func() int { n++; return n * n }).Take(3).Collect()
+	require.Equal(t, []int{1, 4, 9}, generated)
+	require.Equal(t, 3, n)
+
+	require.Equal(t, []string{"x", "y"}, FromSlice([]string{"x", "y"}).Collect())
+}
+
+func TestFromChannel(t *testing.T) {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+	require.Equal(t, []int{1, 2, 3}, FromChannel(context.Background(), ch).Collect())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pending := make(chan int)
+	go func() {
+		pending <- 1
+		cancel()
+	}()
+	require.Equal(t, []int{1}, FromChannel(ctx, pending).Collect())
+}
+
+func TestLines(t *testing.T) {
+	lines, err := Lines(strings.NewReader("first\nsecond\r\n\nlast")).Collect()
+	require.NoError(t, err)
+	require.Equal(t, []string{"first", "second", "", "last"}, lines)
+
+	errRead := errors.New("read failed")
+	r := io.MultiReader(strings.NewReader("ok\n"), iotest.ErrReader(errRead))
+	lines, err = Lines(r).Collect()
+	require.ErrorIs(t, err, errRead)
+	require.Equal(t, []string{"ok"}, lines)
+}
+
+func TestToChannel(t *testing.T) {
+	var received []int
+	for v := range Range(0, 5, 1).ToChannel(context.Background()) {
+		received = append(received, v)
+	}
+	require.Equal(t, []int{0, 1, 2, 3, 4}, received)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	stream := From(func(yield func(int) bool) {
+		defer close(stopped)
+		for i := 0; yield(i); i++ {
+		}
+	})
+
+	ch := stream.ToChannel(ctx)
+	require.Equal(t, 0, <-ch)
+	require.Equal(t, 1, <-ch)
+	cancel()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("upstream was not stopped")
+	}
+	for range ch {
+	}
+}
+
+func TestSourcesCompose(t *testing.T) {
+	result := Range(1, 100, 1).
+		Map(func(x int) int { return x * 2 }).
+		Drop(10).
+		Filter(func(x int) bool { return x%3 == 0 }).
+		Take(5).
+		FoldLeft(func(acc, x int) int { return acc + x })
+	require.Equal(t, 180, result)
+
+	require.Equal(t, []int{0, 1, 2}, slices.Collect(Range(0, 3, 1).Iterate()))
+}
@@ -0,0 +1,342 @@
+package functional
+
+import (
+	"iter"
+	"slices"
+	"time"
+)
+
+// Clock provides time to time-based stream operators.
+// Substitute it with WithClock to control time in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer creates a Timer that fires once after duration d.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event in the future, see time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is delivered when the timer fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing.
+	// It returns false if the timer has already fired or been stopped.
+	Stop() bool
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+// WithClock returns a new stream whose time-based operations, including the ones
+// applied to streams derived from it, use the clock instead of the system one.
+func (s *Stream[T]) WithClock(clock Clock) *Stream[T] {
+	c := s.clone()
+	c.clock = clock
+	return c
+}
+
+func (s *Stream[T]) getClock() Clock {
+	if s.clock == nil {
+		return systemClock{}
+	}
+	return s.clock
+}
+
+// Throttle returns a new stream that emits elements no more often than once per interval,
+// delaying them as needed. No elements are dropped.
+func (s *Stream[T]) Throttle(interval time.Duration) *Stream[T] {
+	clock := s.getClock()
+	return derive(s, "Throttle", func(yield func(T) bool) {
+		var last time.Time
+		first := true
+		for v := range s.seq {
+			if !first {
+				if wait := last.Add(interval).Sub(clock.Now()); wait > 0 {
+					<-clock.NewTimer(wait).C()
+				}
+			}
+			first = false
+			last = clock.Now()
+			if !yield(v) {
+				return
+			}
+		}
+	})
+}
+
+// Debounce returns a new stream that emits an element only after d has passed without
+// a newer one arriving. The last element is emitted as soon as the stream ends.
+//
+// Elements are read from the stream on a separate goroutine.
+func (s *Stream[T]) Debounce(d time.Duration) *Stream[T] {
+	clock := s.getClock()
+	return derive(s, "Debounce", func(yield func(T) bool) {
+		in, stop := pump(s.seq)
+		defer stop()
+
+		var (
+			pending T
+			timer   Timer
+			fired   <-chan time.Time
+		)
+		for {
+			select {
+			case v, ok := <-in:
+				if timer != nil {
+					timer.Stop()
+				}
+				if !ok {
+					if fired != nil {
+						yield(pending)
+					}
+					return
+				}
+				pending = v
+				timer = clock.NewTimer(d)
+				fired = timer.C()
+			case <-fired:
+				timer, fired = nil, nil
+				if !yield(pending) {
+					return
+				}
+			}
+		}
+	})
+}
+
+// Sample returns a new stream that emits the most recent element every period d,
+// if a new one has arrived since the previous emission. An element that arrives
+// after the last emission before the stream ends is not emitted.
+//
+// Elements are read from the stream on a separate goroutine.
+func (s *Stream[T]) Sample(d time.Duration) *Stream[T] {
+	clock := s.getClock()
+	return derive(s, "Sample", func(yield func(T) bool) {
+		in, stop := pump(s.seq)
+		defer stop()
+
+		var (
+			latest T
+			fresh  bool
+		)
+		timer := clock.NewTimer(d)
+		defer func() { timer.Stop() }()
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				latest, fresh = v, true
+			case <-timer.C():
+				timer = clock.NewTimer(d)
+				if fresh {
+					fresh = false
+					if !yield(latest) {
+						return
+					}
+				}
+			}
+		}
+	})
+}
+
+// Buffer returns a new stream of slices of consecutive elements. A slice is emitted
+// when it reaches n elements or when d has passed since its first element arrived,
+// whichever comes first. The rest of the elements is emitted when the stream ends.
+//
+// Non-positive n disables the size limit, and non-positive d disables the time limit.
+//
+// Elements are read from the stream on a separate goroutine.
+func Buffer[T any](s *Stream[T], n int, d time.Duration) *Stream[[]T] {
+	clock := s.getClock()
+	return derive(s, "Buffer", func(yield func([]T) bool) {
+		in, stop := pump(s.seq)
+		defer stop()
+
+		var (
+			buf   []T
+			timer Timer
+			fired <-chan time.Time
+		)
+		flush := func() bool {
+			if timer != nil {
+				timer.Stop()
+				timer, fired = nil, nil
+			}
+			out := buf
+			buf = nil
+			return len(out) == 0 || yield(out)
+		}
+
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				buf = append(buf, v)
+				if len(buf) == 1 && d > 0 {
+					timer = clock.NewTimer(d)
+					fired = timer.C()
+				}
+				if n > 0 && len(buf) >= n && !flush() {
+					return
+				}
+			case <-fired:
+				timer, fired = nil, nil
+				if !flush() {
+					return
+				}
+			}
+		}
+	})
+}
+
+// TimeWindow is a group of elements whose event time is in [Start, End).
+type TimeWindow[T any] struct {
+	Start time.Time
+	End   time.Time
+	Items []T
+}
+
+// TumblingWindows returns a new stream of consecutive non-overlapping windows of the given size,
+// grouping elements by the event time returned by eventTime. Windows are aligned to multiples
+// of size since the zero time, and empty windows are skipped.
+//
+// A window is emitted once an element with event time at or past its end arrives, so
+// elements may arrive out of order as long as their window is still open. Later elements
+// are dropped. Open windows are emitted when the stream ends.
+func TumblingWindows[T any](s *Stream[T], size time.Duration, eventTime func(T) time.Time) *Stream[TimeWindow[T]] {
+	return SlidingWindows(s, size, size, eventTime)
+}
+
+// SlidingWindows returns a new stream of windows of the given size starting every slide,
+// grouping elements by the event time returned by eventTime. An element belongs to every
+// window that covers its event time. Windows are aligned to multiples of slide since the zero
+// time, and empty windows are skipped.
+//
+// Windows are emitted in order of their start the same way as in TumblingWindows.
+//
+// Panics if size or slide is not positive.
+func SlidingWindows[T any](
+	s *Stream[T], size, slide time.Duration, eventTime func(T) time.Time,
+) *Stream[TimeWindow[T]] {
+	if size <= 0 || slide <= 0 {
+		panic("functional: window size and slide must be positive")
+	}
+	return derive(s, "SlidingWindows", func(yield func(TimeWindow[T]) bool) {
+		var (
+			open []*TimeWindow[T] // sorted by start
+			// watermark is the latest event time seen, windows
+			// ending at or before it are closed.
+			watermark time.Time
+		)
+
+		emitUntil := func(t time.Time) bool {
+			for len(open) > 0 && !open[0].End.After(t) {
+				w := open[0]
+				open = open[1:]
+				if !yield(*w) {
+					return false
+				}
+			}
+			return true
+		}
+
+		for v := range s.seq {
+			ts := eventTime(v)
+			if ts.After(watermark) {
+				watermark = ts
+			}
+
+			// Windows covering ts start at multiples of slide in (ts-size, ts].
+			for start := ts.Truncate(slide); start.Add(size).After(ts); start = start.Add(-slide) {
+				if start.Add(size).After(watermark) {
+					open = addToWindow(open, start, size, v)
+				}
+			}
+
+			if !emitUntil(watermark) {
+				return
+			}
+		}
+		for _, w := range open {
+			if !yield(*w) {
+				return
+			}
+		}
+	})
+}
+
+func addToWindow[T any](open []*TimeWindow[T], start time.Time, size time.Duration, v T) []*TimeWindow[T] {
+	i, found := slices.BinarySearchFunc(open, start, func(w *TimeWindow[T], t time.Time) int {
+		return w.Start.Compare(t)
+	})
+	if !found {
+		open = slices.Insert(open, i, &TimeWindow[T]{Start: start, End: start.Add(size)})
+	}
+	open[i].Items = append(open[i].Items, v)
+	return open
+}
+
+// pump reads seq on a new goroutine and sends its elements to the returned channel,
+// which is closed when seq ends. Calling stop makes it stop reading seq and waits
+// for the goroutine to exit, unless it is blocked in seq waiting for an element:
+// then it exits once seq yields or ends.
+func pump[T any](seq iter.Seq[T]) (<-chan T, func()) {
+	var (
+		out  = make(chan T)
+		done = make(chan struct{})
+		gate pullGate
+	)
+	go func() {
+		defer close(out)
+		defer gate.leave()
+
+		if !gate.enter() {
+			return
+		}
+		for v := range seq {
+			gate.leave()
+			select {
+			case out <- v:
+			case <-done:
+				return
+			}
+			if !gate.enter() {
+				return
+			}
+		}
+	}()
+
+	stop := func() {
+		parked := gate.stop()
+		close(done)
+		if !parked {
+			for range out {
+			}
+		}
+	}
+	return out, stop
+}
@@ -0,0 +1,357 @@
+package functional
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a Clock that only moves forward on Advance.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer
+	created int
+}
+
+type fakeTimer struct {
+	clock    *fakeClock
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, deadline: c.now.Add(d), ch: make(chan time.Time, 1)}
+	c.timers = append(c.timers, t)
+	c.created++
+	return t
+}
+
+// Advance moves the clock forward, firing timers that are due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.timers = slices.DeleteFunc(c.timers, func(t *fakeTimer) bool {
+		if t.deadline.After(c.now) {
+			return false
+		}
+		t.ch <- c.now
+		return true
+	})
+}
+
+// WaitTimers blocks until at least n timers have been created in total.
+func (c *fakeClock) WaitTimers(t *testing.T, n int) {
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.created >= n
+	}, time.Second, time.Millisecond)
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	n := len(t.clock.timers)
+	t.clock.timers = slices.DeleteFunc(t.clock.timers, func(other *fakeTimer) bool { return other == t })
+	return len(t.clock.timers) < n
+}
+
+// manualSource returns a stream of values sent to the returned channel,
+// which ends when the channel is closed. Receiving from the acks channel
+// confirms that the stream consumer has taken the last value.
+func manualSource[T any]() (*Stream[T], chan<- T, <-chan struct{}) {
+	in := make(chan T)
+	acks := make(chan struct{})
+	stream := From(func(yield func(T) bool) {
+		for v := range in {
+			if !yield(v) {
+				return
+			}
+			acks <- struct{}{}
+		}
+	})
+	return stream, in, acks
+}
+
+// collectAsync collects elements of the stream on a new goroutine.
+func collectAsync[T any](s *Stream[T]) <-chan T {
+	out := make(chan T, 100)
+	go func() {
+		defer close(out)
+		for v := range s.seq {
+			out <- v
+		}
+	}()
+	return out
+}
+
+func requireNothing[T any](t *testing.T, out <-chan T) {
+	select {
+	case v, ok := <-out:
+		if ok {
+			t.Fatalf("unexpected element %v", v)
+		}
+		t.Fatal("unexpected end of stream")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func requireNext[T any](t *testing.T, out <-chan T) T {
+	select {
+	case v, ok := <-out:
+		require.True(t, ok, "stream ended")
+		return v
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for element")
+	}
+	panic("unreachable")
+}
+
+func requireEnd[T any](t *testing.T, out <-chan T) {
+	select {
+	case v, ok := <-out:
+		require.False(t, ok, "unexpected element %v", v)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for end of stream")
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	clock := newFakeClock()
+	out := collectAsync(Range(1, 4, 1).WithClock(clock).Throttle(time.Second))
+
+	require.Equal(t, 1, requireNext(t, out))
+	clock.WaitTimers(t, 1)
+	requireNothing(t, out)
+
+	clock.Advance(999 * time.Millisecond)
+	requireNothing(t, out)
+	clock.Advance(time.Millisecond)
+	require.Equal(t, 2, requireNext(t, out))
+
+	// No waiting if the interval has already passed.
+	clock.WaitTimers(t, 2)
+	clock.Advance(5 * time.Second)
+	require.Equal(t, 3, requireNext(t, out))
+	requireEnd(t, out)
+}
+
+func TestDebounce(t *testing.T) {
+	clock := newFakeClock()
+	source, in, acks := manualSource[int]()
+	out := collectAsync(source.WithClock(clock).Debounce(100 * time.Millisecond))
+
+	in <- 1
+	<-acks
+	clock.WaitTimers(t, 1)
+	clock.Advance(50 * time.Millisecond)
+
+	in <- 2
+	<-acks
+	clock.WaitTimers(t, 2)
+	clock.Advance(50 * time.Millisecond)
+	requireNothing(t, out)
+	clock.Advance(50 * time.Millisecond)
+	require.Equal(t, 2, requireNext(t, out))
+
+	in <- 3
+	<-acks
+	close(in)
+	require.Equal(t, 3, requireNext(t, out))
+	requireEnd(t, out)
+}
+
+func TestSample(t *testing.T) {
+	clock := newFakeClock()
+	source, in, acks := manualSource[int]()
+	out := collectAsync(source.WithClock(clock).Sample(time.Second))
+
+	clock.WaitTimers(t, 1)
+	in <- 1
+	<-acks
+	in <- 2
+	<-acks
+	clock.Advance(time.Second)
+	require.Equal(t, 2, requireNext(t, out))
+
+	// Nothing new arrived during the period.
+	clock.WaitTimers(t, 2)
+	clock.Advance(time.Second)
+	requireNothing(t, out)
+
+	clock.WaitTimers(t, 3)
+	in <- 3
+	<-acks
+	clock.Advance(time.Second)
+	require.Equal(t, 3, requireNext(t, out))
+
+	in <- 4
+	<-acks
+	close(in)
+	requireEnd(t, out)
+}
+
+func TestBuffer(t *testing.T) {
+	clock := newFakeClock()
+	source, in, acks := manualSource[int]()
+	out := collectAsync(Buffer(source.WithClock(clock), 3, time.Second))
+
+	// Full buffer is emitted right away.
+	for i := 1; i <= 3; i++ {
+		in <- i
+		<-acks
+	}
+	require.Equal(t, []int{1, 2, 3}, requireNext(t, out))
+
+	// Partial buffer is emitted after the timeout.
+	in <- 4
+	<-acks
+	clock.WaitTimers(t, 2)
+	clock.Advance(time.Second)
+	require.Equal(t, []int{4}, requireNext(t, out))
+
+	in <- 5
+	<-acks
+	close(in)
+	require.Equal(t, []int{5}, requireNext(t, out))
+	requireEnd(t, out)
+}
+
+func TestBufferSizeOnly(t *testing.T) {
+	chunks := Buffer(Range(0, 5, 1), 2, 0).Collect()
+	require.Equal(t, [][]int{{0, 1}, {2, 3}, {4}}, chunks)
+}
+
+type event struct {
+	at    time.Time
+	value int
+}
+
+func eventAt(minutes, seconds, value int) event {
+	return event{at: time.Date(2024, 1, 1, 10, minutes, seconds, 0, time.UTC), value: value}
+}
+
+func windowValues(windows []TimeWindow[event]) [][]int {
+	var result [][]int
+	for _, w := range windows {
+		var values []int
+		for _, e := range w.Items {
+			values = append(values, e.value)
+		}
+		result = append(result, values)
+	}
+	return result
+}
+
+func TestTumblingWindows(t *testing.T) {
+	events := FromSlice([]event{
+		eventAt(0, 10, 1),
+		eventAt(0, 50, 2),
+		eventAt(0, 40, 3), // out of order, but its window is still open
+		eventAt(1, 5, 4),
+		eventAt(0, 59, 5), // late, its window is closed
+		eventAt(3, 0, 6),
+	})
+
+	windows := TumblingWindows(events, time.Minute, func(e event) time.Time { return e.at }).Collect()
+	require.Equal(t, [][]int{{1, 2, 3}, {4}, {6}}, windowValues(windows))
+	require.Equal(t, eventAt(1, 0, 0).at, windows[1].Start)
+	require.Equal(t, eventAt(2, 0, 0).at, windows[1].End)
+}
+
+func TestWindowsDropLateElementsOfUnopenedWindows(t *testing.T) {
+	events := FromSlice([]event{
+		eventAt(0, 10, 1),
+		eventAt(3, 0, 2),
+		eventAt(1, 30, 3), // late, its window was never opened but ended before 3:00
+		eventAt(2, 50, 4), // late for the minute window, but the sliding one is open
+	})
+	at := func(e event) time.Time { return e.at }
+
+	tumbling := TumblingWindows(events, time.Minute, at).Collect()
+	require.Equal(t, [][]int{{1}, {2}}, windowValues(tumbling))
+
+	sliding := SlidingWindows(events, 2*time.Minute, time.Minute, at).Collect()
+	require.Equal(t, [][]int{{1}, {1}, {2, 4}, {2}}, windowValues(sliding))
+}
+
+func TestTimeOperatorsStopOnBlockedSource(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(*Stream[int]) *Stream[int]
+	}{
+		{name: "debounce", op: func(s *Stream[int]) *Stream[int] { return s.Debounce(10 * time.Millisecond) }},
+		{name: "sample", op: func(s *Stream[int]) *Stream[int] { return s.Sample(10 * time.Millisecond) }},
+		{name: "buffer", op: func(s *Stream[int]) *Stream[int] {
+			return Map(Buffer(s, 1, time.Second), func(b []int) int { return b[0] })
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ch := make(chan int, 1)
+			ch <- 1
+
+			done := make(chan []int)
+			go func() {
+				done <- tc.op(FromChannel(ctx, ch)).Take(1).Collect()
+			}()
+			select {
+			case result := <-done:
+				require.Equal(t, []int{1}, result)
+			case <-time.After(time.Second):
+				t.Fatal("Take did not stop the stream while its source was blocked")
+			}
+		})
+	}
+}
+
+func TestSlidingWindows(t *testing.T) {
+	events := FromSlice([]event{
+		eventAt(0, 10, 1),
+		eventAt(0, 40, 2),
+		eventAt(1, 10, 3),
+	})
+
+	windows := SlidingWindows(events, time.Minute, 30*time.Second, func(e event) time.Time { return e.at }).Collect()
+	starts := make([]time.Time, 0, len(windows))
+	for _, w := range windows {
+		starts = append(starts, w.Start)
+	}
+	require.Equal(t, []time.Time{
+		eventAt(-1, 30, 0).at,
+		eventAt(0, 0, 0).at,
+		eventAt(0, 30, 0).at,
+		eventAt(1, 0, 0).at,
+	}, starts)
+	require.Equal(t, [][]int{{1}, {1, 2}, {2, 3}, {3}}, windowValues(windows))
+
+	// Infinite streams are fine too.
+	infinite := Map(naturals(), func(i int) event { return eventAt(0, i, i) })
+	first := TumblingWindows(infinite, 10*time.Second, func(e event) time.Time { return e.at }).Take(2).Collect()
+	require.Equal(t, [][]int{{1, 2, 3, 4, 5, 6, 7, 8, 9}, {10, 11, 12, 13, 14, 15, 16, 17, 18, 19}}, windowValues(first))
+}
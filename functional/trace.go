@@ -0,0 +1,160 @@
+package functional
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Tracer records statistics of every stage of a stream: the number of elements
+// that passed through it, the time spent producing them and the first few of them.
+//
+// Attach it with Stream.Trace, run a terminal operation and print the tracer
+// to see a table of the stages.
+type Tracer struct {
+	samples int
+
+	mu     sync.Mutex
+	stages []*stageStats
+}
+
+// StageStats holds statistics of a single stream stage.
+type StageStats struct {
+	// Name is the name of the operation of the stage, or the one set with Named.
+	Name string
+	// Count is the number of elements emitted by the stage.
+	Count int
+	// Elapsed is the time spent producing the elements of the stage,
+	// including the time spent in the stages before it but not after it.
+	Elapsed time.Duration
+	// Samples are the first elements emitted by the stage.
+	Samples []any
+}
+
+type stageStats struct {
+	StageStats
+}
+
+// NewTracer creates a Tracer that keeps up to samples first elements of each stage.
+func NewTracer(samples int) *Tracer {
+	return &Tracer{samples: max(samples, 0)}
+}
+
+// Stages returns statistics of the traced stages in the order they were added.
+func (t *Tracer) Stages() []StageStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stages := make([]StageStats, 0, len(t.stages))
+	for _, st := range t.stages {
+		s := st.StageStats
+		s.Samples = append([]any(nil), s.Samples...)
+		stages = append(stages, s)
+	}
+	return stages
+}
+
+// String formats statistics of the stages as a table.
+func (t *Tracer) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "#\tSTAGE\tCOUNT\tELAPSED\tSAMPLES")
+	for i, st := range t.Stages() {
+		samples := make([]string, 0, len(st.Samples))
+		for _, v := range st.Samples {
+			samples = append(samples, fmt.Sprint(v))
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%d\t%s\t[%s]\n", i, st.Name, st.Count, st.Elapsed, strings.Join(samples, " "))
+	}
+	_ = w.Flush()
+	return b.String()
+}
+
+func (t *Tracer) addStage(name string) *stageStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st := &stageStats{StageStats{Name: name}}
+	t.stages = append(t.stages, st)
+	return st
+}
+
+func (t *Tracer) record(st *stageStats, v any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st.Count++
+	if len(st.Samples) < t.samples {
+		st.Samples = append(st.Samples, v)
+	}
+}
+
+func (t *Tracer) addElapsed(st *stageStats, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st.Elapsed += d
+}
+
+func (t *Tracer) rename(st *stageStats, name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st.Name = name
+}
+
+// traced wraps values to record their statistics in st.
+func traced[T any](t *Tracer, st *stageStats, values iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		var downstream time.Duration
+		start := time.Now()
+		defer func() {
+			t.addElapsed(st, time.Since(start)-downstream)
+		}()
+
+		for v := range values {
+			t.record(st, v)
+			yieldStart := time.Now()
+			ok := yield(v)
+			downstream += time.Since(yieldStart)
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// Trace returns a new stream that records statistics of its stages in the tracer,
+// starting with the source stage and including all operations applied to the stream
+// and the streams derived from it.
+func (s *Stream[T]) Trace(t *Tracer) *Stream[T] {
+	c := s.clone()
+	c.tracer = t
+	c.fused = nil
+	c.stage = t.addStage("Source")
+	c.seq = traced(t, c.stage, s.seq)
+	return c
+}
+
+// Named sets the name under which the last operation of the stream is traced.
+// It has no effect if the stream is not traced.
+func (s *Stream[T]) Named(name string) *Stream[T] {
+	if s.tracer != nil && s.stage != nil {
+		s.tracer.rename(s.stage, name)
+	}
+	return s
+}
+
+// Peek returns a new stream with the same elements that calls the function f
+// on each of them as they pass through.
+func (s *Stream[T]) Peek(f func(T)) *Stream[T] {
+	return derive(s, "Peek", func(yield func(T) bool) {
+		for v := range s.seq {
+			f(v)
+			if !yield(v) {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package functional
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeek(t *testing.T) {
+	var seen []int
+	result := slices.Collect(From(slices.Values([]int{1, 2, 3, 4})).
+		Peek(func(x int) { seen = append(seen, x) }).
+		Take(2).
+		Iterate())
+
+	require.Equal(t, []int{1, 2}, result)
+	require.Equal(t, []int{1, 2}, seen)
+}
+
+func TestTracer(t *testing.T) {
+	tracer := NewTracer(2)
+	stream := NewStream(slices.Values([]int{1, 2, 3, 4, 5, 6, 7})).Trace(tracer)
+
+	it := stream.
+		Map(func(x int) int { return x * 3 }).Named("Triple").
+		Filter(func(x int) bool { return x%2 != 0 }).Named("Odd").
+		Drop(1).
+		Take(2).
+		Map(func(x int) int { return x + 1 }).
+		Iterate()
+
+	require.Equal(t, []int{10, 16}, slices.Collect(it))
+
+	type stage struct {
+		name    string
+		count   int
+		samples []any
+	}
+	var stages []stage
+	for _, st := range tracer.Stages() {
+		require.GreaterOrEqual(t, st.Elapsed, time.Duration(0))
+		stages = append(stages, stage{st.Name, st.Count, st.Samples})
+	}
+	require.Equal(t, []stage{
+		{"Source", 5, []any{1, 2}},
+		{"Triple", 5, []any{3, 6}},
+		{"Odd", 3, []any{3, 9}},
+		{"Drop", 2, []any{9, 15}},
+		{"Take", 2, []any{9, 15}},
+		{"Map", 2, []any{10, 16}},
+	}, stages)
+
+	lines := strings.Split(strings.TrimSpace(tracer.String()), "\n")
+	require.Len(t, lines, 7)
+	require.Equal(t, []string{"#", "STAGE", "COUNT", "ELAPSED", "SAMPLES"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"2", "Odd", "3"}, strings.Fields(lines[3])[:3])
+	require.True(t, strings.HasSuffix(lines[3], "[3 9]"))
+}
+
+func TestTracerNotInherited(t *testing.T) {
+	tracer := NewTracer(1)
+	source := From(slices.Values([]int{1, 2, 3}))
+	_ = source.Trace(tracer)
+
+	require.Equal(t, []int{2, 4, 6}, slices.Collect(source.Map(func(x int) int { return x * 2 }).Iterate()))
+	require.Len(t, tracer.Stages(), 1)
+	require.Zero(t, tracer.Stages()[0].Count)
+
+	// Named has no effect on untraced streams.
+	require.Equal(t, []int{1, 2, 3}, slices.Collect(source.Named("Numbers").Iterate()))
+}
@@ -0,0 +1,133 @@
+package functional
+
+import (
+	"errors"
+	"iter"
+)
+
+// TryStream represents a lazy sequence of values produced by operations that may fail.
+// Every element is either a value or an error.
+//
+// Errors flow through TryMap and TryFilter untouched, and terminal operations decide
+// whether to stop on the first one or to collect all of them.
+type TryStream[T any] struct {
+	seq iter.Seq2[T, error]
+}
+
+// TryFrom creates a new TryStream from a sequence of values and errors.
+// Values paired with a non-nil error are ignored.
+func TryFrom[T any](values iter.Seq2[T, error]) *TryStream[T] {
+	return &TryStream[T]{seq: values}
+}
+
+// Try returns a TryStream with the elements of the stream and no errors.
+func (s *Stream[T]) Try() *TryStream[T] {
+	return TryFrom(func(yield func(T, error) bool) {
+		for v := range s.seq {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	})
+}
+
+// TryMap applies the function f to each value in the stream and returns a new stream
+// with the results or errors returned by f.
+func TryMap[T, U any](s *TryStream[T], f func(T) (U, error)) *TryStream[U] {
+	return TryFrom(func(yield func(U, error) bool) {
+		for v, err := range s.seq {
+			var u U
+			if err == nil {
+				u, err = f(v)
+			}
+			if !yield(u, err) {
+				return
+			}
+		}
+	})
+}
+
+// TryMap applies the function f to each value in the stream and returns a new stream
+// with the results or errors returned by f.
+func (s *TryStream[T]) TryMap(f func(T) (T, error)) *TryStream[T] {
+	return TryMap(s, f)
+}
+
+// TryFilter returns a new stream containing only the values that satisfy the predicate,
+// along with all errors, including the ones returned by the predicate.
+func (s *TryStream[T]) TryFilter(predicate func(T) (bool, error)) *TryStream[T] {
+	return TryFrom(func(yield func(T, error) bool) {
+		for v, err := range s.seq {
+			if err != nil {
+				var zero T
+				if !yield(zero, err) {
+					return
+				}
+				continue
+			}
+
+			keep, err := predicate(v)
+			switch {
+			case err != nil:
+				var zero T
+				if !yield(zero, err) {
+					return
+				}
+			case keep:
+				if !yield(v, nil) {
+					return
+				}
+			}
+		}
+	})
+}
+
+// Iterate returns an iterator over the values and errors in the stream.
+func (s *TryStream[T]) Iterate() iter.Seq2[T, error] {
+	return s.seq
+}
+
+// ForEach applies the function f to each value in the stream.
+//
+// Stops at the first error in the stream or returned by f, and returns it.
+func (s *TryStream[T]) ForEach(f func(T) error) error {
+	for v, err := range s.seq {
+		if err == nil {
+			err = f(v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Collect returns the values of the stream.
+//
+// Stops at the first error and returns it along with the values read before it.
+func (s *TryStream[T]) Collect() ([]T, error) {
+	var values []T
+	err := s.ForEach(func(v T) error {
+		values = append(values, v)
+		return nil
+	})
+	return values, err
+}
+
+// CollectAll returns all values of the stream and all errors in it joined with errors.Join.
+//
+// The error is nil if there were no errors.
+func (s *TryStream[T]) CollectAll() ([]T, error) {
+	var (
+		values []T
+		errs   []error
+	)
+	for v, err := range s.seq {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		values = append(values, v)
+	}
+	return values, errors.Join(errs...)
+}
@@ -0,0 +1,115 @@
+package functional
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parseInts(input []string) *TryStream[int] {
+	return TryMap(From(slices.Values(input)).Try(), strconv.Atoi)
+}
+
+func TestTryMap(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     []string
+		expected  []int
+		expectErr bool
+	}{
+		{
+			name:     "all_valid",
+			input:    []string{"1", "2", "3"},
+			expected: []int{1, 2, 3},
+		},
+		{
+			name:      "stops_at_error",
+			input:     []string{"1", "x", "3"},
+			expected:  []int{1},
+			expectErr: true,
+		},
+		{
+			name:  "empty",
+			input: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := parseInts(tc.input).Collect()
+			require.Equal(t, tc.expected, values)
+			if tc.expectErr {
+				require.ErrorIs(t, err, strconv.ErrSyntax)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTryShortCircuit(t *testing.T) {
+	var mapped []int
+	stream := parseInts([]string{"1", "2", "bad", "4"}).TryMap(func(x int) (int, error) {
+		mapped = append(mapped, x)
+		return x * 10, nil
+	})
+
+	values, err := stream.Collect()
+	require.Error(t, err)
+	require.Equal(t, []int{10, 20}, values)
+	require.Equal(t, []int{1, 2}, mapped)
+}
+
+func TestTryFilter(t *testing.T) {
+	errOdd := errors.New("odd number")
+	stream := parseInts([]string{"2", "3", "x", "4", "6"}).TryFilter(func(x int) (bool, error) {
+		if x%2 != 0 {
+			return false, fmt.Errorf("%d: %w", x, errOdd)
+		}
+		return x > 2, nil
+	})
+
+	values, err := stream.CollectAll()
+	require.Equal(t, []int{4, 6}, values)
+	require.ErrorIs(t, err, errOdd)
+	require.ErrorIs(t, err, strconv.ErrSyntax)
+
+	var joined interface{ Unwrap() []error }
+	require.ErrorAs(t, err, &joined)
+	require.Len(t, joined.Unwrap(), 2)
+}
+
+func TestTryForEach(t *testing.T) {
+	errStop := errors.New("stop")
+	var seen []int
+
+	err := parseInts([]string{"1", "2", "3"}).ForEach(func(x int) error {
+		seen = append(seen, x)
+		if x == 2 {
+			return errStop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, []int{1, 2}, seen)
+}
+
+func TestTryIterate(t *testing.T) {
+	var errs int
+	for v, err := range parseInts([]string{"1", "?", "3"}).Iterate() {
+		if err != nil {
+			errs++
+			continue
+		}
+		require.Positive(t, v)
+	}
+	require.Equal(t, 1, errs)
+
+	values, err := parseInts([]string{"7"}).CollectAll()
+	require.NoError(t, err)
+	require.Equal(t, []int{7}, values)
+}
@@ -0,0 +1,348 @@
+package functional
+
+import (
+	"iter"
+	"slices"
+)
+
+const (
+	vectorBits  = 5
+	vectorWidth = 1 << vectorBits
+	vectorMask  = vectorWidth - 1
+)
+
+// editToken marks nodes owned by a transient, which may update them in place.
+// It must not be zero sized, so that every token has a distinct address.
+type editToken struct {
+	_ byte
+}
+
+// Vector is an immutable sequence of values. Methods that change the vector
+// return a new one, sharing most of its structure with the original,
+// so they take effectively constant time.
+//
+// Elements are stored in a 32-way trie, with the last up to 32 elements kept
+// in a separate tail to make appends cheap. The zero Vector is empty and ready to use.
+//
+// Use a TransientVector to build a vector from many values.
+type Vector[T any] struct {
+	count int
+	shift uint
+	root  *vectorNode[T]
+	// tail holds the last elements, it is never modified in place.
+	tail []T
+}
+
+type vectorNode[T any] struct {
+	edit *editToken
+	// children are set for internal nodes and values for leaves.
+	children []*vectorNode[T]
+	values   []T
+}
+
+// VectorOf creates a new Vector with the values.
+func VectorOf[T any](values ...T) Vector[T] {
+	return CollectVector(slices.Values(values))
+}
+
+// CollectVector creates a new Vector with the values of the sequence.
+func CollectVector[T any](values iter.Seq[T]) Vector[T] {
+	t := Vector[T]{}.Transient()
+	for v := range values {
+		t.Append(v)
+	}
+	return t.Persistent()
+}
+
+// Len returns the number of elements in the vector.
+func (v Vector[T]) Len() int {
+	return v.count
+}
+
+// Get returns the element at index i.
+//
+// Panics if i is out of range.
+func (v Vector[T]) Get(i int) T {
+	checkIndex(i, v.count)
+	return v.leafFor(i)[i&vectorMask]
+}
+
+// Set returns a new vector with the element at index i replaced by value.
+//
+// Panics if i is out of range.
+func (v Vector[T]) Set(i int, value T) Vector[T] {
+	checkIndex(i, v.count)
+	if i >= v.tailOffset() {
+		v.tail = slices.Clone(v.tail)
+		v.tail[i&vectorMask] = value
+		return v
+	}
+	v.root = setInNode(nil, v.shift, v.root, i, value)
+	return v
+}
+
+// Append returns a new vector with the values added to the end.
+func (v Vector[T]) Append(values ...T) Vector[T] {
+	if len(values) > 1 {
+		t := v.Transient()
+		for _, value := range values {
+			t.Append(value)
+		}
+		return t.Persistent()
+	}
+	for _, value := range values {
+		if len(v.tail) < vectorWidth {
+			tail := make([]T, len(v.tail)+1, vectorWidth)
+			copy(tail, v.tail)
+			tail[len(v.tail)] = value
+			v.tail = tail
+		} else {
+			v.root, v.shift = pushTail(nil, v.count, v.shift, v.root, &vectorNode[T]{values: v.tail})
+			v.tail = []T{value}
+		}
+		v.count++
+	}
+	return v
+}
+
+// Pop returns a new vector without the last element.
+//
+// Panics if the vector is empty.
+func (v Vector[T]) Pop() Vector[T] {
+	if v.count == 0 {
+		panic("functional: pop from empty vector")
+	}
+	if v.count == 1 {
+		return Vector[T]{}
+	}
+	if len(v.tail) > 1 {
+		// The tail is never modified in place, so it can be shared.
+		v.tail = v.tail[: len(v.tail)-1 : len(v.tail)-1]
+		v.count--
+		return v
+	}
+
+	v.tail = v.leafFor(v.count - 2)
+	v.root = popTail(nil, v.count, v.shift, v.root)
+	if v.shift > vectorBits && v.root.children[1] == nil {
+		v.root = v.root.children[0]
+		v.shift -= vectorBits
+	}
+	v.count--
+	return v
+}
+
+// All returns an iterator over indexes and elements of the vector.
+func (v Vector[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < v.count; i += vectorWidth {
+			for j, value := range v.leafFor(i) {
+				if !yield(i+j, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Values returns an iterator over elements of the vector, which can be used
+// as a source of a Stream.
+func (v Vector[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, value := range v.All() {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Transient returns a TransientVector with the elements of the vector.
+// The vector itself is not affected by changes of the transient.
+func (v Vector[T]) Transient() *TransientVector[T] {
+	tail := make([]T, len(v.tail), vectorWidth)
+	copy(tail, v.tail)
+	return &TransientVector[T]{
+		edit: &editToken{},
+		vec:  Vector[T]{count: v.count, shift: v.shift, root: v.root, tail: tail},
+	}
+}
+
+func (v Vector[T]) tailOffset() int {
+	return v.count - len(v.tail)
+}
+
+// leafFor returns the slice of up to 32 elements holding element i.
+func (v Vector[T]) leafFor(i int) []T {
+	if i >= v.tailOffset() {
+		return v.tail
+	}
+	node := v.root
+	for level := v.shift; level > 0; level -= vectorBits {
+		node = node.children[(i>>level)&vectorMask]
+	}
+	return node.values
+}
+
+// TransientVector is a mutable builder of a Vector. It updates nodes it
+// has created in place, which makes bulk changes much faster than
+// with a Vector, and shares the rest with the vector it was created from.
+//
+// A TransientVector must not be used after Persistent is called,
+// and it is not safe for concurrent use.
+type TransientVector[T any] struct {
+	edit *editToken
+	vec  Vector[T]
+}
+
+// Len returns the number of elements in the transient.
+func (t *TransientVector[T]) Len() int {
+	t.ensureEditable()
+	return t.vec.count
+}
+
+// Get returns the element at index i.
+//
+// Panics if i is out of range.
+func (t *TransientVector[T]) Get(i int) T {
+	t.ensureEditable()
+	return t.vec.Get(i)
+}
+
+// Set replaces the element at index i with value.
+//
+// Panics if i is out of range.
+func (t *TransientVector[T]) Set(i int, value T) {
+	t.ensureEditable()
+	checkIndex(i, t.vec.count)
+	if i >= t.vec.tailOffset() {
+		t.vec.tail[i&vectorMask] = value
+		return
+	}
+	t.vec.root = setInNode(t.edit, t.vec.shift, t.vec.root, i, value)
+}
+
+// Append adds the value to the end.
+func (t *TransientVector[T]) Append(value T) {
+	t.ensureEditable()
+	if len(t.vec.tail) == vectorWidth {
+		leaf := &vectorNode[T]{edit: t.edit, values: t.vec.tail}
+		t.vec.root, t.vec.shift = pushTail(t.edit, t.vec.count, t.vec.shift, t.vec.root, leaf)
+		t.vec.tail = make([]T, 0, vectorWidth)
+	}
+	t.vec.tail = append(t.vec.tail, value)
+	t.vec.count++
+}
+
+// Persistent returns a Vector with the elements of the transient
+// and invalidates the transient.
+func (t *TransientVector[T]) Persistent() Vector[T] {
+	t.ensureEditable()
+	t.edit = nil
+	v := t.vec
+	v.tail = slices.Clip(v.tail)
+	return v
+}
+
+func (t *TransientVector[T]) ensureEditable() {
+	if t.edit == nil {
+		panic("functional: transient used after Persistent")
+	}
+}
+
+// editableNode returns node if it is owned by edit, or its copy owned by edit.
+// A nil edit always copies.
+func editableNode[T any](edit *editToken, node *vectorNode[T]) *vectorNode[T] {
+	if edit != nil && node.edit == edit {
+		return node
+	}
+	return &vectorNode[T]{
+		edit:     edit,
+		children: slices.Clone(node.children),
+		values:   slices.Clone(node.values),
+	}
+}
+
+func newInternalNode[T any](edit *editToken) *vectorNode[T] {
+	return &vectorNode[T]{edit: edit, children: make([]*vectorNode[T], vectorWidth)}
+}
+
+func setInNode[T any](edit *editToken, level uint, node *vectorNode[T], i int, value T) *vectorNode[T] {
+	node = editableNode(edit, node)
+	if level == 0 {
+		node.values[i&vectorMask] = value
+		return node
+	}
+	sub := (i >> level) & vectorMask
+	node.children[sub] = setInNode(edit, level-vectorBits, node.children[sub], i, value)
+	return node
+}
+
+// pushTail adds the full tail leaf to the trie of a vector of count elements
+// and returns the new root and shift.
+func pushTail[T any](
+	edit *editToken, count int, shift uint, root, leaf *vectorNode[T],
+) (*vectorNode[T], uint) {
+	if root == nil {
+		root, shift = newInternalNode[T](edit), vectorBits
+	}
+	// The root is full, the trie grows by a level.
+	if count>>vectorBits > 1<<shift {
+		newRoot := newInternalNode[T](edit)
+		newRoot.children[0] = root
+		newRoot.children[1] = newPath(edit, shift, leaf)
+		return newRoot, shift + vectorBits
+	}
+	return pushLeaf(edit, count, shift, root, leaf), shift
+}
+
+func pushLeaf[T any](edit *editToken, count int, level uint, node, leaf *vectorNode[T]) *vectorNode[T] {
+	node = editableNode(edit, node)
+	sub := ((count - 1) >> level) & vectorMask
+	switch child := node.children[sub]; {
+	case level == vectorBits:
+		node.children[sub] = leaf
+	case child != nil:
+		node.children[sub] = pushLeaf(edit, count, level-vectorBits, child, leaf)
+	default:
+		node.children[sub] = newPath(edit, level-vectorBits, leaf)
+	}
+	return node
+}
+
+// newPath returns a chain of nodes from level down to the leaf.
+func newPath[T any](edit *editToken, level uint, leaf *vectorNode[T]) *vectorNode[T] {
+	if level == 0 {
+		return leaf
+	}
+	node := newInternalNode[T](edit)
+	node.children[0] = newPath(edit, level-vectorBits, leaf)
+	return node
+}
+
+// popTail removes the last leaf from the trie of a vector of count elements.
+// Returns nil if the node becomes empty.
+func popTail[T any](edit *editToken, count int, level uint, node *vectorNode[T]) *vectorNode[T] {
+	sub := ((count - 2) >> level) & vectorMask
+	if level > vectorBits {
+		child := popTail(edit, count, level-vectorBits, node.children[sub])
+		if child == nil && sub == 0 {
+			return nil
+		}
+		node = editableNode(edit, node)
+		node.children[sub] = child
+		return node
+	}
+	if sub == 0 {
+		return nil
+	}
+	node = editableNode(edit, node)
+	node.children[sub] = nil
+	return node
+}
+
+func checkIndex(i, length int) {
+	if i < 0 || i >= length {
+		panic("functional: index out of range")
+	}
+}
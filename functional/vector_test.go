@@ -0,0 +1,115 @@
+package functional
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVector(t *testing.T) {
+	for _, n := range []int{0, 1, 31, 32, 33, 1024, 1025, 32*32*32 + 100} {
+		v := CollectVector(Range(0, n, 1).Iterate())
+		require.Equal(t, n, v.Len())
+		require.Equal(t, slices.Collect(Range(0, n, 1).Iterate()), slices.Collect(v.Values()))
+		for i := 0; i < n; i += max(n/100, 1) {
+			require.Equal(t, i, v.Get(i))
+		}
+	}
+}
+
+func TestVectorPersistence(t *testing.T) {
+	v1 := VectorOf(1, 2, 3)
+	v2 := v1.Append(4)
+	v3 := v1.Set(0, 10)
+	v4 := v2.Pop().Pop()
+
+	require.Equal(t, []int{1, 2, 3}, slices.Collect(v1.Values()))
+	require.Equal(t, []int{1, 2, 3, 4}, slices.Collect(v2.Values()))
+	require.Equal(t, []int{10, 2, 3}, slices.Collect(v3.Values()))
+	require.Equal(t, []int{1, 2}, slices.Collect(v4.Values()))
+
+	var empty Vector[string]
+	require.Zero(t, empty.Len())
+	require.Equal(t, []string{"a"}, slices.Collect(empty.Append("a").Values()))
+	require.Panics(t, func() { empty.Get(0) })
+	require.Panics(t, func() { empty.Pop() })
+	require.Panics(t, func() { v1.Set(3, 0) })
+}
+
+func TestVectorRandomOps(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	var (
+		v        Vector[int]
+		expected []int
+		versions []Vector[int]
+		models   [][]int
+	)
+	for i := range 20000 {
+		switch op := r.IntN(10); {
+		case op < 6 || len(expected) == 0:
+			v = v.Append(i)
+			expected = append(expected, i)
+		case op < 8:
+			j := r.IntN(len(expected))
+			v = v.Set(j, -i)
+			expected[j] = -i
+		default:
+			v = v.Pop()
+			expected = expected[:len(expected)-1]
+		}
+		if i%1000 == 0 {
+			versions = append(versions, v)
+			models = append(models, slices.Clone(expected))
+		}
+	}
+
+	require.Equal(t, expected, slices.Collect(v.Values()))
+	for i, version := range versions {
+		require.Equal(t, models[i], slices.Collect(version.Values()))
+	}
+}
+
+func TestTransientVector(t *testing.T) {
+	base := CollectVector(Range(0, 100, 1).Iterate())
+
+	tr := base.Transient()
+	for i := 100; i < 2000; i++ {
+		tr.Append(i)
+	}
+	tr.Set(0, -1)
+	tr.Set(1999, -2)
+	require.Equal(t, 2000, tr.Len())
+	require.Equal(t, -2, tr.Get(1999))
+
+	v := tr.Persistent()
+	require.Panics(t, func() { tr.Append(0) })
+
+	// Changes of another transient don't leak into the vector.
+	other := v.Transient()
+	other.Set(500, 0)
+	other.Append(0)
+	require.Equal(t, 500, v.Get(500))
+	require.Equal(t, 2000, v.Len())
+
+	expected := slices.Collect(Range(0, 2000, 1).Iterate())
+	expected[0], expected[1999] = -1, -2
+	require.Equal(t, expected, slices.Collect(v.Values()))
+	require.Equal(t, slices.Collect(Range(0, 100, 1).Iterate()), slices.Collect(base.Values()))
+}
+
+func TestVectorStream(t *testing.T) {
+	v := VectorOf(1, 2, 3, 4, 5)
+	sum := NewStream(v.Values()).Filter(func(x int) bool { return x%2 != 0 }).FoldLeft(func(a, b int) int { return a + b })
+	require.Equal(t, 9, sum)
+
+	var indexes []int
+	for i := range v.All() {
+		if i == 2 {
+			break
+		}
+		indexes = append(indexes, i)
+	}
+	require.Equal(t, []int{0, 1}, indexes)
+}
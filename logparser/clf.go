@@ -0,0 +1,91 @@
+package logparser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// clfPattern matches the common log format, optionally followed by the referer
+// and user agent of the combined log format.
+var clfPattern = regexp.MustCompile(
+	`^(\S+) (\S+) (\S+) \[([^\]]+)\] "((?:[^"\\]|\\.)*)" (\d{3}) (\d+|-)` +
+		`(?: "((?:[^"\\]|\\.)*)" "((?:[^"\\]|\\.)*)")?$`,
+)
+
+// CLFParser parses access logs of nginx and Apache in the common
+// or combined log format:
+//
+//	127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /index.html HTTP/1.0" 200 2326
+//
+// The level is derived from the status: 5xx are Error, 4xx are Warn
+// and others are Info. The message is the part after the timestamp.
+//
+// The parts of the line also become fields: "host", "ident", "user", "method",
+// "path", "protocol", "status", "bytes", "referer" and "user_agent", with status
+// and bytes as integers. Parts that are "-" or missing are left out.
+type CLFParser struct{}
+
+func (CLFParser) Parse(line string) (LogEntry, error) {
+	m := clfPattern.FindStringSubmatchIndex(line)
+	if m == nil {
+		return LogEntry{}, invalidLine("common log", line)
+	}
+	ts, err := time.Parse(clfTimeLayout, line[m[8]:m[9]])
+	if err != nil {
+		return LogEntry{}, invalidLine("common log", line)
+	}
+
+	var level LogLevel = LogLevelInfo
+	switch line[m[12]] {
+	case '5':
+		level = LogLevelError
+	case '4':
+		level = LogLevelWarn
+	}
+	// The message starts with the quoted request line.
+	message := strings.TrimSpace(line[m[10]-1:])
+	return LogEntry{Timestamp: ts, Level: level, Message: message, Fields: clfFields(line, m)}, nil
+}
+
+// clfFields returns the fields of a line matched by clfPattern.
+func clfFields(line string, m []int) Fields {
+	group := func(i int) string {
+		if m[2*i] < 0 {
+			return "-"
+		}
+		return line[m[2*i]:m[2*i+1]]
+	}
+
+	var fields Fields
+	add := func(key, s string, value Value) {
+		if s != "-" && s != "" {
+			fields = append(fields, Field{Key: key, Value: value})
+		}
+	}
+	str := func(key, s string) {
+		add(key, s, StringValue(s))
+	}
+	num := func(key, s string) {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			add(key, s, IntValue(n))
+		}
+	}
+
+	str("host", group(1))
+	str("ident", group(2))
+	str("user", group(3))
+	if request := strings.Fields(group(5)); len(request) == 3 {
+		str("method", request[0])
+		str("path", request[1])
+		str("protocol", request[2])
+	}
+	num("status", group(6))
+	num("bytes", group(7))
+	str("referer", group(8))
+	str("user_agent", group(9))
+	return fields
+}
@@ -0,0 +1,255 @@
+package logparser
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the type of a field Value.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindFloat
+	KindBool
+	KindDuration
+	KindTime
+)
+
+var kindNames = [...]string{"string", "int", "float", "bool", "duration", "time"}
+
+func (k Kind) String() string {
+	if k >= 0 && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Value is a typed value of a field: a string, int, float, bool, duration or time.
+// The zero Value is an empty string.
+type Value struct {
+	kind Kind
+	str  string
+	// num holds ints, durations and bools, float holds floats.
+	num   int64
+	float float64
+	time  time.Time
+}
+
+// StringValue returns a Value holding the string.
+func StringValue(v string) Value {
+	return Value{kind: KindString, str: v}
+}
+
+// IntValue returns a Value holding the integer.
+func IntValue(v int64) Value {
+	return Value{kind: KindInt, num: v}
+}
+
+// FloatValue returns a Value holding the float.
+func FloatValue(v float64) Value {
+	return Value{kind: KindFloat, float: v}
+}
+
+// BoolValue returns a Value holding the bool.
+func BoolValue(v bool) Value {
+	var num int64
+	if v {
+		num = 1
+	}
+	return Value{kind: KindBool, num: num}
+}
+
+// DurationValue returns a Value holding the duration.
+func DurationValue(v time.Duration) Value {
+	return Value{kind: KindDuration, num: int64(v)}
+}
+
+// TimeValue returns a Value holding the time.
+func TimeValue(v time.Time) Value {
+	return Value{kind: KindTime, time: v}
+}
+
+// Kind returns the type of the value.
+func (v Value) Kind() Kind {
+	return v.kind
+}
+
+// Str returns the string held by the value.
+// The second value is false if it holds another type.
+func (v Value) Str() (string, bool) {
+	return v.str, v.kind == KindString
+}
+
+// Int returns the integer held by the value.
+// The second value is false if it holds another type.
+func (v Value) Int() (int64, bool) {
+	return v.num, v.kind == KindInt
+}
+
+// Float returns the float held by the value, converting integers.
+// The second value is false if it holds another type.
+func (v Value) Float() (float64, bool) {
+	switch v.kind {
+	case KindFloat:
+		return v.float, true
+	case KindInt:
+		return float64(v.num), true
+	default:
+		return 0, false
+	}
+}
+
+// Bool returns the bool held by the value.
+// The second value is false if it holds another type.
+func (v Value) Bool() (bool, bool) {
+	return v.num != 0, v.kind == KindBool
+}
+
+// Duration returns the duration held by the value.
+// The second value is false if it holds another type.
+func (v Value) Duration() (time.Duration, bool) {
+	return time.Duration(v.num), v.kind == KindDuration
+}
+
+// Time returns the time held by the value.
+// The second value is false if it holds another type.
+func (v Value) Time() (time.Time, bool) {
+	return v.time, v.kind == KindTime
+}
+
+// Any returns the value as a string, int64, float64, bool, time.Duration or time.Time.
+func (v Value) Any() any {
+	switch v.kind {
+	case KindInt:
+		return v.num
+	case KindFloat:
+		return v.float
+	case KindBool:
+		return v.num != 0
+	case KindDuration:
+		return time.Duration(v.num)
+	case KindTime:
+		return v.time
+	default:
+		return v.str
+	}
+}
+
+// String formats the value the way it would appear in a log.
+func (v Value) String() string {
+	switch v.kind {
+	case KindInt:
+		return strconv.FormatInt(v.num, 10)
+	case KindFloat:
+		return strconv.FormatFloat(v.float, 'g', -1, 64)
+	case KindBool:
+		return strconv.FormatBool(v.num != 0)
+	case KindDuration:
+		return time.Duration(v.num).String()
+	case KindTime:
+		return v.time.Format(time.RFC3339Nano)
+	default:
+		return v.str
+	}
+}
+
+// Field is a key-value pair of a structured log entry.
+type Field struct {
+	Key   string
+	Value Value
+}
+
+// Fields are the structured attributes of a log entry in the order
+// they appeared in the log line.
+type Fields []Field
+
+// Get returns the value of the first field with the key.
+// The second value is false if there is no such field.
+func (f Fields) Get(key string) (Value, bool) {
+	for _, field := range f {
+		if field.Key == key {
+			return field.Value, true
+		}
+	}
+	return Value{}, false
+}
+
+// Str returns the string value of the field with the key,
+// or def if it is missing or not a string.
+func (f Fields) Str(key, def string) string {
+	return getOr(f, key, def, Value.Str)
+}
+
+// Int returns the integer value of the field with the key,
+// or def if it is missing or not an integer.
+func (f Fields) Int(key string, def int64) int64 {
+	return getOr(f, key, def, Value.Int)
+}
+
+// Float returns the float or integer value of the field with the key
+// as a float, or def if it is missing or not a number.
+func (f Fields) Float(key string, def float64) float64 {
+	return getOr(f, key, def, Value.Float)
+}
+
+// Bool returns the bool value of the field with the key,
+// or def if it is missing or not a bool.
+func (f Fields) Bool(key string, def bool) bool {
+	return getOr(f, key, def, Value.Bool)
+}
+
+// Duration returns the duration value of the field with the key,
+// or def if it is missing or not a duration.
+func (f Fields) Duration(key string, def time.Duration) time.Duration {
+	return getOr(f, key, def, Value.Duration)
+}
+
+// Time returns the time value of the field with the key,
+// or def if it is missing or not a time.
+func (f Fields) Time(key string, def time.Time) time.Time {
+	return getOr(f, key, def, Value.Time)
+}
+
+func getOr[T any](f Fields, key string, def T, get func(Value) (T, bool)) T {
+	v, ok := f.Get(key)
+	if !ok {
+		return def
+	}
+	if typed, ok := get(v); ok {
+		return typed
+	}
+	return def
+}
+
+// inferValue guesses the type of a textual value, like the ones of logfmt:
+// integers, floats, bools, durations like "1.5s" and RFC 3339 times are
+// recognized, anything else is a string.
+func inferValue(s string) Value {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return IntValue(i)
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) &&
+		strings.ContainsAny(s, "0123456789") {
+		return FloatValue(f)
+	}
+	if s == "true" || s == "false" {
+		return BoolValue(s == "true")
+	}
+	return inferTextValue(s)
+}
+
+// inferTextValue recognizes durations and times in strings, which have
+// no type of their own in JSON.
+func inferTextValue(s string) Value {
+	if d, err := time.ParseDuration(s); err == nil && s != "0" {
+		return DurationValue(d)
+	}
+	if t, ok := parseTimestamp(s); ok {
+		return TimeValue(t)
+	}
+	return StringValue(s)
+}
@@ -0,0 +1,112 @@
+package logparser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValue(t *testing.T) {
+	at := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    Value
+		kind     Kind
+		expected any
+		str      string
+	}{
+		{name: "zero", value: Value{}, kind: KindString, expected: "", str: ""},
+		{name: "string", value: StringValue("abc"), kind: KindString, expected: "abc", str: "abc"},
+		{name: "int", value: IntValue(-7), kind: KindInt, expected: int64(-7), str: "-7"},
+		{name: "float", value: FloatValue(0.5), kind: KindFloat, expected: 0.5, str: "0.5"},
+		{name: "bool", value: BoolValue(true), kind: KindBool, expected: true, str: "true"},
+		{name: "duration", value: DurationValue(90 * time.Second), kind: KindDuration, expected: 90 * time.Second, str: "1m30s"},
+		{name: "time", value: TimeValue(at), kind: KindTime, expected: at, str: "2024-01-01T10:00:00Z"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.kind, tc.value.Kind())
+			require.Equal(t, tc.expected, tc.value.Any())
+			require.Equal(t, tc.str, tc.value.String())
+		})
+	}
+
+	_, ok := StringValue("1").Int()
+	require.False(t, ok)
+	f, ok := IntValue(3).Float()
+	require.True(t, ok)
+	require.Equal(t, 3.0, f)
+	_, ok = BoolValue(false).Str()
+	require.False(t, ok)
+	require.Equal(t, "Kind(42)", Kind(42).String())
+}
+
+func TestFieldsGetters(t *testing.T) {
+	at := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	fields := Fields{
+		{Key: "user", Value: StringValue("alice")},
+		{Key: "rows", Value: IntValue(12)},
+		{Key: "ratio", Value: FloatValue(0.25)},
+		{Key: "cached", Value: BoolValue(true)},
+		{Key: "took", Value: DurationValue(time.Second)},
+		{Key: "at", Value: TimeValue(at)},
+		{Key: "user", Value: StringValue("bob")},
+	}
+
+	v, ok := fields.Get("user")
+	require.True(t, ok)
+	require.Equal(t, StringValue("alice"), v)
+	_, ok = fields.Get("missing")
+	require.False(t, ok)
+
+	require.Equal(t, "alice", fields.Str("user", "-"))
+	require.Equal(t, "-", fields.Str("rows", "-"))
+	require.Equal(t, int64(12), fields.Int("rows", 0))
+	require.Equal(t, int64(-1), fields.Int("ratio", -1))
+	require.Equal(t, 0.25, fields.Float("ratio", 0))
+	require.Equal(t, 12.0, fields.Float("rows", 0))
+	require.True(t, fields.Bool("cached", false))
+	require.True(t, fields.Bool("missing", true))
+	require.Equal(t, time.Second, fields.Duration("took", 0))
+	require.Equal(t, time.Minute, fields.Duration("rows", time.Minute))
+	require.Equal(t, at, fields.Time("at", time.Time{}))
+	require.Equal(t, time.Time{}, fields.Time("user", time.Time{}))
+
+	var empty Fields
+	require.Equal(t, "def", empty.Str("user", "def"))
+}
+
+func TestInferValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Value
+	}{
+		{name: "int", input: "42", expected: IntValue(42)},
+		{name: "negative_int", input: "-3", expected: IntValue(-3)},
+		{name: "float", input: "1.5", expected: FloatValue(1.5)},
+		{name: "exponent", input: "1e3", expected: FloatValue(1000)},
+		{name: "inf", input: "Inf", expected: StringValue("Inf")},
+		{name: "nan", input: "NaN", expected: StringValue("NaN")},
+		{name: "true", input: "true", expected: BoolValue(true)},
+		{name: "capitalized_bool", input: "True", expected: StringValue("True")},
+		{name: "zero", input: "0", expected: IntValue(0)},
+		{name: "duration", input: "1h2m", expected: DurationValue(time.Hour + 2*time.Minute)},
+		{name: "time", input: "2024-01-01T10:00:00Z", expected: TimeValue(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))},
+		{name: "string", input: "hello", expected: StringValue("hello")},
+		{name: "empty", input: "", expected: StringValue("")},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, inferValue(tc.input))
+		})
+	}
+
+	require.Equal(t, StringValue("42"), inferTextValue("42"))
+	require.Equal(t, StringValue("0"), inferTextValue("0"))
+	require.Equal(t, DurationValue(time.Millisecond), inferTextValue("1ms"))
+}
@@ -0,0 +1,460 @@
+package logparser
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultPollInterval is how often a followed file is checked for new data.
+const defaultPollInterval = 250 * time.Millisecond
+
+// errLineBreak is returned by follower between files when the first one
+// doesn't end with a newline, so that its last line isn't joined with
+// the first line of the next one. Readers of lines treat it as a newline.
+var errLineBreak = errors.New("logparser: line broken by the end of a file")
+
+// FollowOptions configure how AddFile follows a file.
+type FollowOptions struct {
+	// Parser parses the lines of the file, TextParser if nil.
+	Parser Parser
+	// PollInterval is how often the file is checked for new data and rotation,
+	// 250ms if zero.
+	PollInterval time.Duration
+	// FromEnd starts reading at the end of the file, like tail, instead of
+	// its beginning. It is ignored if reading resumes from StateFile.
+	FromEnd bool
+	// StateFile is the path of a file to save the offset of the last entry,
+	// or of the skipped and filtered lines after it, to, so that reading
+	// resumes after it when AddFile is called again, even if the file was
+	// rotated in between. Empty disables saving.
+	StateFile string
+	// ReadRotated reads the predecessors of the file rotated by logrotate,
+	// like "app.log.2.gz" and "app.log.1", oldest first, before the file
+	// itself. It is ignored if reading resumes from StateFile or FromEnd is set.
+	ReadRotated bool
+	// SourceOptions configure the source, which is named after the path
+	// unless they set another name.
+	SourceOptions []SourceOption
+}
+
+// AddFile adds the file at path as a source that is followed like tail -F:
+// after reaching its end, the reader waits for more lines to be appended.
+// If the file is truncated, it is read again from the beginning, and if it
+// is renamed or removed and created again, the new file is read once the old
+// one is exhausted. The file doesn't need to exist when AddFile is called.
+//
+// Files are checked for changes every FollowOptions.PollInterval. The source
+// ends only when the reader is closed.
+//
+// AddFile fails if the file or StateFile can't be read. Errors writing the state
+// are ignored, and saving is retried on the next check.
+func (lr *LogReader) AddFile(path string, opts FollowOptions) error {
+	if opts.Parser == nil {
+		opts.Parser = TextParser{}
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+
+	f := &follower{path: path, opts: opts, done: lr.done}
+	if err := f.start(); err != nil {
+		return err
+	}
+	lr.mu.Lock()
+	lr.closers = append(lr.closers, f.close)
+	lr.mu.Unlock()
+
+	srcOpts := append([]SourceOption{WithName(path), withCommit(f.commit)}, opts.SourceOptions...)
+	lr.AddSourceWithParser(f, opts.Parser, srcOpts...)
+	return nil
+}
+
+// withCommit sets the function told about entries that left the reader.
+func withCommit(commit func(end int64)) SourceOption {
+	return func(s *source) {
+		s.commit = commit
+	}
+}
+
+// followState is the content of FollowOptions.StateFile.
+type followState struct {
+	Inode  uint64 `json:"inode"`
+	Offset int64  `json:"offset"`
+}
+
+// followFile is a file read by follower.
+type followFile struct {
+	path   string
+	gzip   bool
+	inode  uint64
+	offset int64
+
+	file   *os.File
+	reader io.Reader
+}
+
+func (ff *followFile) open() error {
+	file, err := os.Open(ff.path)
+	if err != nil {
+		return err
+	}
+	ff.file, ff.reader = file, file
+	if ff.gzip {
+		if ff.reader, err = gzip.NewReader(file); err != nil {
+			file.Close()
+			return err
+		}
+		return nil
+	}
+
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	ff.inode = fileInode(fi)
+	if ff.offset > fi.Size() {
+		ff.offset = 0
+	}
+	if _, err := file.Seek(ff.offset, io.SeekStart); err != nil {
+		file.Close()
+		return err
+	}
+	return nil
+}
+
+func (ff *followFile) close() {
+	if ff.file != nil {
+		ff.file.Close()
+		ff.file, ff.reader = nil, nil
+	}
+}
+
+// followSegment is a part of the data returned by follower read from one file.
+type followSegment struct {
+	// start is the position in the returned data where the segment starts,
+	// and offset is the position in the file it was read from.
+	start, offset int64
+	inode         uint64
+	// saved is false for compressed files, whose offsets aren't saved.
+	saved bool
+}
+
+// follower is an io.Reader of a followed file and its rotated predecessors,
+// which blocks at the end of the file until more data is written.
+type follower struct {
+	path string
+	opts FollowOptions
+	done <-chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+	// queue holds the files read before the followed one.
+	queue []*followFile
+	cur   *followFile
+	// live is set if cur is the file at path rather than a predecessor.
+	live bool
+	// read is the number of bytes returned so far.
+	read int64
+	// partial is set if the returned data doesn't end with a newline,
+	// and broken if the line must be broken before the next data.
+	partial, broken bool
+	segments        []followSegment
+	// committed is the position in the returned data up to which
+	// entries left the reader.
+	committed int64
+	saved     followState
+	savedAt   time.Time
+}
+
+// start prepares the files to read, resuming from the saved state if any.
+func (f *follower) start() error {
+	state, resume, err := f.loadState()
+	if err != nil {
+		return err
+	}
+	f.saved = state
+
+	fi, err := os.Stat(f.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	exists := err == nil
+
+	switch {
+	case resume:
+		switch {
+		case exists && f.matches(fi, state):
+			f.queue = append(f.queue, &followFile{path: f.path, offset: state.Offset})
+			return nil
+		case f.opts.ReadRotated:
+			// The file was rotated since the state was saved, but its
+			// first predecessor may be the one we were reading.
+			if prev, err := os.Stat(f.path + ".1"); err == nil && f.matches(prev, state) {
+				f.queue = append(f.queue, &followFile{path: f.path + ".1", offset: state.Offset})
+			}
+		}
+	case f.opts.FromEnd:
+		if exists {
+			f.queue = append(f.queue, &followFile{path: f.path, offset: fi.Size()})
+			return nil
+		}
+	case f.opts.ReadRotated:
+		rotated, err := f.rotated()
+		if err != nil {
+			return err
+		}
+		f.queue = append(f.queue, rotated...)
+	}
+	if exists {
+		f.queue = append(f.queue, &followFile{path: f.path})
+	}
+	return nil
+}
+
+// matches reports whether the state was saved for the file.
+func (f *follower) matches(fi os.FileInfo, state followState) bool {
+	return fileInode(fi) == state.Inode && fi.Size() >= state.Offset
+}
+
+func (f *follower) loadState() (followState, bool, error) {
+	var state followState
+	if f.opts.StateFile == "" {
+		return state, false, nil
+	}
+	data, err := os.ReadFile(f.opts.StateFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, false, nil
+	}
+	if err != nil {
+		return state, false, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, false, fmt.Errorf("logparser: invalid state file %s: %w", f.opts.StateFile, err)
+	}
+	return state, true, nil
+}
+
+// rotated returns the predecessors of the file, oldest first.
+func (f *follower) rotated() ([]*followFile, error) {
+	matches, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	var files []*followFile
+	numbers := make(map[*followFile]int)
+	for _, m := range matches {
+		suffix, gz := strings.CutSuffix(strings.TrimPrefix(m, f.path+"."), ".gz")
+		n, err := strconv.Atoi(suffix)
+		if err != nil || n <= 0 {
+			continue
+		}
+		ff := &followFile{path: m, gzip: gz}
+		numbers[ff] = n
+		files = append(files, ff)
+	}
+	slices.SortFunc(files, func(a, b *followFile) int {
+		return numbers[b] - numbers[a]
+	})
+	return files, nil
+}
+
+func (f *follower) Read(p []byte) (int, error) {
+	for {
+		n, wait, err := f.next(p)
+		if n > 0 || err != nil {
+			f.saveStateEvery(f.opts.PollInterval)
+			return n, err
+		}
+		if wait {
+			f.saveState()
+			select {
+			case <-f.done:
+				return 0, io.EOF
+			case <-time.After(f.opts.PollInterval):
+			}
+		}
+	}
+}
+
+// next reads from the current file, moving to the next one when it ends.
+// It returns wait if there is nothing to read until the file changes.
+func (f *follower) next(p []byte) (n int, wait bool, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, false, io.EOF
+	}
+
+	if f.cur == nil {
+		if !f.openNext() {
+			return 0, true, nil
+		}
+	}
+	if f.broken {
+		f.partial, f.broken = false, false
+		return 0, false, errLineBreak
+	}
+
+	n, err = f.cur.reader.Read(p)
+	if n > 0 {
+		f.cur.offset += int64(n)
+		f.read += int64(n)
+		f.partial = p[n-1] != '\n'
+		return n, false, nil
+	}
+	if err != nil && err != io.EOF {
+		return 0, false, err
+	}
+
+	if !f.live {
+		f.cur.close()
+		f.cur = nil
+		return 0, false, nil
+	}
+	return 0, !f.rotate(), nil
+}
+
+// openNext opens the next file of the queue, or the file at path
+// if the queue is empty. Predecessors that fail to open are skipped.
+func (f *follower) openNext() bool {
+	for len(f.queue) > 0 {
+		ff := f.queue[0]
+		f.queue = f.queue[1:]
+		if err := ff.open(); err == nil {
+			f.use(ff, len(f.queue) == 0 && ff.path == f.path)
+			return true
+		}
+	}
+	ff := &followFile{path: f.path}
+	if err := ff.open(); err != nil {
+		return false
+	}
+	f.use(ff, true)
+	return true
+}
+
+func (f *follower) use(ff *followFile, live bool) {
+	f.cur, f.live = ff, live
+	f.broken = f.partial
+	f.segments = append(f.segments, followSegment{
+		start: f.read, offset: ff.offset, inode: ff.inode, saved: !ff.gzip,
+	})
+}
+
+// rotate checks the file at path once the current one is exhausted.
+// It reports whether there may be more to read.
+func (f *follower) rotate() bool {
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		// Removed or renamed, wait for the new file.
+		return false
+	}
+	cur, err := f.cur.file.Stat()
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case !os.SameFile(fi, cur):
+		// Lines may have been appended between reaching the end
+		// and the rename, the next read gets them or ends the file.
+		if cur.Size() > f.cur.offset {
+			return true
+		}
+		f.cur.close()
+		f.cur = nil
+		return true
+	case fi.Size() < f.cur.offset:
+		if _, err := f.cur.file.Seek(0, io.SeekStart); err != nil {
+			return false
+		}
+		f.cur.offset = 0
+		f.use(f.cur, true)
+		return true
+	default:
+		return false
+	}
+}
+
+// commit records that entries up to the position end of the returned data
+// left the reader.
+func (f *follower) commit(end int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.committed = max(f.committed, end)
+}
+
+// saveState writes the position of the last committed entry to the state file.
+func (f *follower) saveState() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.saveLocked()
+}
+
+// saveStateEvery saves the state if it wasn't saved for the interval,
+// so that it is saved now and then while the file is busy.
+func (f *follower) saveStateEvery(interval time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.savedAt.Add(interval).Before(time.Now()) {
+		f.saveLocked()
+	}
+}
+
+func (f *follower) saveLocked() {
+	// Drop the segments the committed position has left behind.
+	i := len(f.segments) - 1
+	for i > 0 && f.segments[i].start > f.committed {
+		i--
+	}
+	if i < 0 {
+		return
+	}
+	f.segments = f.segments[i:]
+	seg := f.segments[0]
+	if f.opts.StateFile == "" || !seg.saved || seg.start > f.committed {
+		return
+	}
+
+	f.savedAt = time.Now()
+	state := followState{Inode: seg.inode, Offset: seg.offset + f.committed - seg.start}
+	if state == f.saved {
+		return
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	tmp := f.opts.StateFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, f.opts.StateFile); err != nil {
+		return
+	}
+	f.saved = state
+}
+
+// close saves the state and closes the files once the reader is closed,
+// when no more entries can leave it.
+func (f *follower) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.saveLocked()
+	f.closed = true
+	if f.cur != nil {
+		f.cur.close()
+	}
+}
@@ -0,0 +1,171 @@
+package logparser
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const followPoll = 10 * time.Millisecond
+
+func logLine(second int, message string) string {
+	return fmt.Sprintf("2024-01-01 10:00:%02d INFO %s\n", second, message)
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func messages(entries []LogEntry) []string {
+	var result []string
+	for _, e := range entries {
+		result = append(result, e.Message)
+	}
+	return result
+}
+
+func TestAddFileFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	reader := NewLogReader()
+	defer reader.Close()
+	require.NoError(t, reader.AddFile(path, FollowOptions{PollInterval: followPoll}))
+
+	// The file appears after AddFile and grows, with a line written in parts.
+	appendFile(t, path, logLine(0, "created"))
+	require.Equal(t, []string{"created"}, messages(readExactlyN(t, reader.Stream(), 1)))
+	appendFile(t, path, logLine(1, "appended")+"2024-01-01 10:00:02 INFO ha")
+	require.Equal(t, []string{"appended"}, messages(readExactlyN(t, reader.Stream(), 1)))
+	appendFile(t, path, "lf\n")
+	require.Equal(t, []string{"half"}, messages(readExactlyN(t, reader.Stream(), 1)))
+
+	// Truncation starts over.
+	require.NoError(t, os.WriteFile(path, []byte(logLine(3, "truncated")), 0o644))
+	require.Equal(t, []string{"truncated"}, messages(readExactlyN(t, reader.Stream(), 1)))
+
+	// Rotation by renaming, with a line written to the old file late.
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendFile(t, path+".1", logLine(4, "late in old"))
+	appendFile(t, path, logLine(5, "new file"))
+	entries := readExactlyN(t, reader.Stream(), 2)
+	require.Equal(t, []string{"late in old", "new file"}, messages(entries))
+	require.Equal(t, path, entries[1].Source)
+
+	// Removal and creation.
+	require.NoError(t, os.Remove(path))
+	time.Sleep(5 * followPoll)
+	appendFile(t, path, logLine(6, "recreated"))
+	require.Equal(t, []string{"recreated"}, messages(readExactlyN(t, reader.Stream(), 1)))
+	require.Equal(t, SourceReading, reader.Sources()[0].Status)
+}
+
+func TestAddFileFromEnd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, logLine(0, "old"))
+
+	reader := NewLogReader()
+	defer reader.Close()
+	require.NoError(t, reader.AddFile(path, FollowOptions{PollInterval: followPoll, FromEnd: true}))
+	time.Sleep(5 * followPoll)
+
+	appendFile(t, path, logLine(1, "new"))
+	require.Equal(t, []string{"new"}, messages(readExactlyN(t, reader.Stream(), 1)))
+}
+
+func TestAddFileRotated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	for i, n := range []int{3, 2} {
+		f, err := os.Create(fmt.Sprintf("%s.%d.gz", path, n))
+		require.NoError(t, err)
+		zw := gzip.NewWriter(f)
+		_, err = zw.Write([]byte(logLine(i, fmt.Sprintf("gzip %d", n))))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		require.NoError(t, f.Close())
+	}
+	appendFile(t, path+".1", logLine(2, "plain 1"))
+	appendFile(t, path+".old", logLine(0, "not rotated"))
+	appendFile(t, path, logLine(3, "current"))
+
+	reader := NewLogReader()
+	defer reader.Close()
+	require.NoError(t, reader.AddFile(path, FollowOptions{PollInterval: followPoll, ReadRotated: true}))
+	require.Equal(t, []string{"gzip 3", "gzip 2", "plain 1", "current"}, messages(readExactlyN(t, reader.Stream(), 4)))
+}
+
+func TestAddFileState(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	opts := FollowOptions{PollInterval: followPoll, StateFile: filepath.Join(dir, "state.json"), ReadRotated: true}
+	appendFile(t, path, logLine(0, "first")+logLine(1, "second"))
+
+	follow := func(n int) []string {
+		reader := NewLogReader()
+		defer reader.Close()
+		require.NoError(t, reader.AddFile(path, opts))
+		return messages(readExactlyN(t, reader.Stream(), n))
+	}
+
+	require.Equal(t, []string{"first", "second"}, follow(2))
+
+	appendFile(t, path, logLine(2, "third"))
+	require.Equal(t, []string{"third"}, follow(1))
+
+	// Rotated while not running: the rest of the old file, then the new one.
+	appendFile(t, path, logLine(3, "fourth"))
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendFile(t, path, logLine(4, "fifth"))
+	require.Equal(t, []string{"fourth", "fifth"}, follow(2))
+
+	require.Empty(t, follow(0))
+
+	require.NoError(t, os.WriteFile(opts.StateFile, []byte("garbage"), 0o644))
+	reader := NewLogReader()
+	defer reader.Close()
+	require.ErrorContains(t, reader.AddFile(path, opts), "invalid state file")
+}
+
+func TestAddFileRotatedWithoutNewline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, logLine(0, "first")+"2024-01-01 10:00:01 INFO unterminated")
+
+	reader := NewLogReader()
+	defer reader.Close()
+	require.NoError(t, reader.AddFile(path, FollowOptions{PollInterval: followPoll}))
+	require.Equal(t, []string{"first"}, messages(readExactlyN(t, reader.Stream(), 1)))
+
+	// The last line of the old file ends with it.
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendFile(t, path, logLine(2, "new file"))
+	require.Equal(t, []string{"unterminated", "new file"}, messages(readExactlyN(t, reader.Stream(), 2)))
+}
+
+func TestAddFileStateSkippedLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	stateFile := filepath.Join(dir, "state.json")
+	data := logLine(0, "first") + "garbage\n" + logLine(1, "filtered") + "\n"
+	appendFile(t, path, data)
+
+	reader := NewLogReader(WithFilter(MustParseQuery(`message != "filtered"`).Match))
+	defer reader.Close()
+	require.NoError(t, reader.AddFile(path, FollowOptions{PollInterval: followPoll, StateFile: stateFile}))
+	require.Equal(t, []string{"first"}, messages(readExactlyN(t, reader.Stream(), 1)))
+
+	// Lines without entries are committed too.
+	require.Eventually(t, func() bool {
+		state, err := os.ReadFile(stateFile)
+		return err == nil && strings.Contains(string(state), fmt.Sprintf(`"offset":%d`, len(data)))
+	}, time.Second, followPoll)
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package logparser
+
+import "os"
+
+// fileInode returns 0, since inode numbers are unknown on this platform.
+// Saved offsets are then trusted if the file is at least as long.
+func fileInode(os.FileInfo) uint64 {
+	return 0
+}
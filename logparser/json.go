@@ -0,0 +1,220 @@
+package logparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	timeKeys    = []string{"time", "timestamp", "ts", "@timestamp"}
+	levelKeys   = []string{"level", "lvl", "severity"}
+	messageKeys = []string{"msg", "message"}
+)
+
+// JSONParser parses lines holding a JSON object, like the ones written by
+// log/slog, zap or logrus:
+//
+//	{"time":"2024-01-01T10:00:00Z","level":"INFO","msg":"This is an info message"}
+//
+// The timestamp is taken from the first of the "time", "timestamp", "ts" and
+// "@timestamp" keys, and is either an RFC 3339 string or a Unix time. The unit
+// of Unix times is told by their magnitude, so that seconds of zap, milliseconds
+// of pino and bunyan, and microseconds and nanoseconds are all recognized for
+// times after 1973. The level is taken from "level", "lvl" or "severity"
+// and is Info if missing. Numeric levels of pino and bunyan are recognized:
+// 10 and 20 are Debug, 30 is Info, 40 is Warn, and 50 and 60 are Error.
+// The message is taken from "msg" or "message".
+//
+// Other keys become fields. Keys of nested objects are joined with dots,
+// like "http.status", arrays are kept as JSON strings and nulls are skipped.
+// Numbers are ints if they are integral and floats otherwise, strings holding
+// durations like "1.5s" or RFC 3339 times become durations and times.
+type JSONParser struct{}
+
+func (JSONParser) Parse(line string) (LogEntry, error) {
+	if !strings.HasPrefix(strings.TrimSpace(line), "{") {
+		return LogEntry{}, invalidLine("JSON", line)
+	}
+	var fields []jsonField
+	if err := decodeJSONObject([]byte(line), "", &fields); err != nil {
+		return LogEntry{}, invalidLine("JSON", line)
+	}
+
+	entry := LogEntry{Level: LogLevelInfo}
+	tsField := takeJSONField(&fields, timeKeys)
+	switch ts := tsField.(type) {
+	case string:
+		t, ok := parseTimestamp(ts)
+		if !ok {
+			return LogEntry{}, invalidLine("JSON", line)
+		}
+		entry.Timestamp = t
+	case json.Number:
+		t, ok := parseUnixTime(ts)
+		if !ok {
+			return LogEntry{}, invalidLine("JSON", line)
+		}
+		entry.Timestamp = t
+	default:
+		return LogEntry{}, invalidLine("JSON", line)
+	}
+
+	switch level := takeJSONField(&fields, levelKeys).(type) {
+	case string:
+		var ok bool
+		if entry.Level, ok = parseLevel(level); !ok {
+			return LogEntry{}, invalidLine("JSON", line)
+		}
+	case json.Number:
+		var ok bool
+		if entry.Level, ok = parseNumericLevel(level); !ok {
+			return LogEntry{}, invalidLine("JSON", line)
+		}
+	}
+	entry.Message, _ = takeJSONField(&fields, messageKeys).(string)
+
+	for _, f := range fields {
+		if v, ok := jsonValue(f.value); ok {
+			entry.Fields = append(entry.Fields, Field{Key: f.key, Value: v})
+		}
+	}
+	return entry, nil
+}
+
+// unixTimeUnits are the units of Unix times, with the least magnitude
+// of times in each unit after 1973. Seconds are the default.
+var unixTimeUnits = []struct {
+	min  float64
+	unit time.Duration
+}{
+	{1e17, time.Nanosecond},
+	{1e14, time.Microsecond},
+	{1e11, time.Millisecond},
+}
+
+// parseUnixTime parses a Unix time, guessing its unit by its magnitude.
+func parseUnixTime(n json.Number) (time.Time, bool) {
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	unit := time.Second
+	for _, u := range unixTimeUnits {
+		if math.Abs(f) >= u.min {
+			unit = u.unit
+			break
+		}
+	}
+	// Integers are converted exactly, floats lose precision beyond microseconds.
+	if i, err := n.Int64(); err == nil {
+		perSecond := int64(time.Second / unit)
+		return time.Unix(i/perSecond, i%perSecond*int64(unit)).UTC(), true
+	}
+	sec, frac := math.Modf(f * float64(unit) / float64(time.Second))
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC(), true
+}
+
+// parseNumericLevel parses the numeric levels of pino and bunyan,
+// from 10 for trace to 60 for fatal.
+func parseNumericLevel(n json.Number) (LogLevel, bool) {
+	switch n {
+	case "10", "20":
+		return LogLevelDebug, true
+	case "30":
+		return LogLevelInfo, true
+	case "40":
+		return LogLevelWarn, true
+	case "50", "60":
+		return LogLevelError, true
+	default:
+		return 0, false
+	}
+}
+
+type jsonField struct {
+	key   string
+	value any
+}
+
+// decodeJSONObject appends the keys and values of the JSON object to fields
+// in the order of the object, flattening nested objects.
+func decodeJSONObject(data []byte, prefix string, fields *[]jsonField) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := prefix + token.(string)
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		switch raw[0] {
+		case '{':
+			if err := decodeJSONObject(raw, key+".", fields); err != nil {
+				return err
+			}
+		case '[':
+			*fields = append(*fields, jsonField{key, string(raw)})
+		default:
+			var v any
+			vdec := json.NewDecoder(bytes.NewReader(raw))
+			vdec.UseNumber()
+			if err := vdec.Decode(&v); err != nil {
+				return err
+			}
+			*fields = append(*fields, jsonField{key, v})
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	// Anything after the object makes the line invalid.
+	if _, err := dec.Token(); err == nil {
+		return errTrailingData
+	}
+	return nil
+}
+
+var errTrailingData = errors.New("data after JSON object")
+
+// takeJSONField removes the field with the first present of keys
+// and returns its value, or nil if there is none.
+func takeJSONField(fields *[]jsonField, keys []string) any {
+	for _, key := range keys {
+		for i, f := range *fields {
+			if f.key == key {
+				*fields = append((*fields)[:i], (*fields)[i+1:]...)
+				return f.value
+			}
+		}
+	}
+	return nil
+}
+
+func jsonValue(v any) (Value, bool) {
+	switch v := v.(type) {
+	case string:
+		return inferTextValue(v), true
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return IntValue(i), true
+		}
+		f, err := v.Float64()
+		return FloatValue(f), err == nil
+	case bool:
+		return BoolValue(v), true
+	default:
+		return Value{}, false
+	}
+}
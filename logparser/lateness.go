@@ -0,0 +1,50 @@
+package logparser
+
+import (
+	"strconv"
+	"time"
+)
+
+// LatePolicy decides what LogReader does with late entries, the ones older
+// than an entry it has already emitted.
+type LatePolicy int
+
+const (
+	// LateEmit emits late entries out of order, with Late set.
+	LateEmit LatePolicy = iota
+	// LateDrop skips late entries.
+	LateDrop
+	// LateSideChannel sends late entries to the channel returned by Late
+	// instead of Stream. A source stalls until its late entry is read from
+	// the channel, and entries of the other sources don't wait for it
+	// meanwhile, so they may make its next entries late too.
+	LateSideChannel
+)
+
+var latePolicyNames = [...]string{"emit", "drop", "side channel"}
+
+func (p LatePolicy) String() string {
+	if p >= 0 && int(p) < len(latePolicyNames) {
+		return latePolicyNames[p]
+	}
+	return "LatePolicy(" + strconv.Itoa(int(p)) + ")"
+}
+
+// WithAllowedLateness limits how long entries wait for sources that have
+// no entry ready. Once an entry has waited d, the earliest of the ready
+// entries is emitted without them, so a blocked source can't hold back
+// the others forever. Entries such a source produces later may be late.
+//
+// Without this option, entries wait for all sources, however long it takes.
+func WithAllowedLateness(d time.Duration) ReaderOption {
+	return func(lr *LogReader) {
+		lr.bounded, lr.lateness = true, max(d, 0)
+	}
+}
+
+// WithLatePolicy sets what happens to late entries. The default is LateEmit.
+func WithLatePolicy(p LatePolicy) ReaderOption {
+	return func(lr *LogReader) {
+		lr.latePolicy = p
+	}
+}
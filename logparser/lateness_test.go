@@ -0,0 +1,123 @@
+package logparser
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowedLateness(t *testing.T) {
+	const fast = "2024-01-01 10:00:00 INFO a\n2024-01-01 10:00:05 INFO b\n"
+
+	t.Run("unbounded", func(t *testing.T) {
+		silent, w := io.Pipe()
+		defer w.Close()
+
+		reader := NewLogReader()
+		defer reader.Close()
+		reader.AddSource(strings.NewReader(fast))
+		reader.AddSource(silent)
+
+		readExactlyN(t, reader.Stream(), 0)
+	})
+
+	tests := []struct {
+		name     string
+		policy   LatePolicy
+		expected []LogEntry
+		late     []LogEntry
+	}{
+		{
+			name:   "emit",
+			policy: LateEmit,
+			expected: []LogEntry{
+				{Timestamp: mustParseTime("2024-01-01 10:00:01"), Level: LogLevelWarn, Message: "slow", Late: true},
+				{Timestamp: mustParseTime("2024-01-01 10:00:06"), Level: LogLevelInfo, Message: "on time"},
+			},
+		},
+		{
+			name:     "drop",
+			policy:   LateDrop,
+			expected: []LogEntry{{Timestamp: mustParseTime("2024-01-01 10:00:06"), Level: LogLevelInfo, Message: "on time"}},
+		},
+		{
+			name:     "side_channel",
+			policy:   LateSideChannel,
+			expected: []LogEntry{{Timestamp: mustParseTime("2024-01-01 10:00:06"), Level: LogLevelInfo, Message: "on time"}},
+			late:     []LogEntry{{Timestamp: mustParseTime("2024-01-01 10:00:01"), Level: LogLevelWarn, Message: "slow"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			slow, w := io.Pipe()
+			defer w.Close()
+
+			reader := NewLogReader(WithAllowedLateness(20*time.Millisecond), WithLatePolicy(tc.policy))
+			defer reader.Close()
+			reader.AddSource(strings.NewReader(fast))
+			reader.AddSource(slow)
+
+			entries := readExactlyN(t, reader.Stream(), 2)
+			require.Equal(t, []string{"a", "b"}, []string{entries[0].Message, entries[1].Message})
+
+			go func() {
+				_, _ = w.Write([]byte("2024-01-01 10:00:01 WARN slow\n2024-01-01 10:00:06 INFO on time\n"))
+			}()
+			var late []LogEntry
+			if tc.late != nil {
+				late = readExactlyN(t, reader.Late(), len(tc.late))
+			}
+			require.Equal(t, tc.expected, readExactlyN(t, reader.Stream(), len(tc.expected)))
+			require.Equal(t, tc.late, late)
+			require.Equal(t, 1, reader.Sources()[1].Late)
+		})
+	}
+}
+
+func TestLateSourceAdded(t *testing.T) {
+	reader := NewLogReader()
+	defer reader.Close()
+	reader.AddSource(strings.NewReader("2024-01-01 10:00:05 INFO first\n"))
+	readExactlyN(t, reader.Stream(), 1)
+
+	reader.AddSource(strings.NewReader("2024-01-01 10:00:00 INFO earlier\n2024-01-01 10:00:05 INFO same time\n"))
+	entries := readExactlyN(t, reader.Stream(), 2)
+	require.True(t, entries[0].Late)
+	require.False(t, entries[1].Late)
+}
+
+func TestLatePolicyString(t *testing.T) {
+	require.Equal(t, "emit", LateEmit.String())
+	require.Equal(t, "side channel", LateSideChannel.String())
+	require.Equal(t, "LatePolicy(5)", LatePolicy(5).String())
+}
+
+func TestLateSideChannelUnread(t *testing.T) {
+	first, w1 := io.Pipe()
+	defer w1.Close()
+	second, w2 := io.Pipe()
+	defer w2.Close()
+
+	reader := NewLogReader(WithLatePolicy(LateSideChannel))
+	defer reader.Close()
+	reader.AddSource(first)
+	reader.AddSource(second)
+
+	go func() {
+		_, _ = w1.Write([]byte("2024-01-01 10:00:05 INFO a\n"))
+		_, _ = w1.Write([]byte("2024-01-01 10:00:01 WARN late\n"))
+	}()
+	go func() {
+		_, _ = w2.Write([]byte("2024-01-01 10:00:06 INFO b\n"))
+	}()
+
+	// The late entry of the first source is never read from Late,
+	// but the entry of the second source doesn't wait for it.
+	entries := readExactlyN(t, reader.Stream(), 2)
+	require.Equal(t, []string{"a", "b"}, messages(entries))
+	require.Equal(t, 1, reader.Sources()[0].Late)
+}
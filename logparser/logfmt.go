@@ -0,0 +1,112 @@
+package logparser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// LogfmtParser parses lines of key=value pairs separated by spaces,
+// with values containing spaces in double quotes:
+//
+//	time=2024-01-01T10:00:00Z level=info msg="This is an info message"
+//
+// The timestamp, level and message are taken from the same keys as by JSONParser,
+// and the timestamp must be in RFC 3339 format. Other pairs become fields,
+// with types of their values guessed: integers, floats, true and false,
+// durations like "1.5s" and RFC 3339 times are recognized, anything else
+// is a string. Quoted values are strings unless they hold durations or times.
+// Keys without a value, like "debug" in "debug msg=hi", get an empty string.
+type LogfmtParser struct{}
+
+func (LogfmtParser) Parse(line string) (LogEntry, error) {
+	pairs, ok := parseLogfmt(line)
+	if !ok {
+		return LogEntry{}, invalidLine("logfmt", line)
+	}
+	get := func(keys []string) (string, bool) {
+		for _, key := range keys {
+			for i, p := range pairs {
+				if p.key == key {
+					pairs = append(pairs[:i], pairs[i+1:]...)
+					return p.value, true
+				}
+			}
+		}
+		return "", false
+	}
+
+	entry := LogEntry{Level: LogLevelInfo}
+	ts, _ := get(timeKeys)
+	if entry.Timestamp, ok = parseTimestamp(ts); !ok {
+		return LogEntry{}, invalidLine("logfmt", line)
+	}
+	if level, found := get(levelKeys); found {
+		if entry.Level, ok = parseLevel(level); !ok {
+			return LogEntry{}, invalidLine("logfmt", line)
+		}
+	}
+	entry.Message, _ = get(messageKeys)
+
+	for _, p := range pairs {
+		value := inferValue(p.value)
+		if p.quoted {
+			// Quoted numbers and bools are meant to be strings.
+			value = inferTextValue(p.value)
+		}
+		entry.Fields = append(entry.Fields, Field{Key: p.key, Value: value})
+	}
+	return entry, nil
+}
+
+type logfmtPair struct {
+	key, value string
+	quoted     bool
+}
+
+// parseLogfmt splits the line into key-value pairs. Keys without a value,
+// like "debug" in "debug msg=hi", get an empty one.
+func parseLogfmt(line string) ([]logfmtPair, bool) {
+	var pairs []logfmtPair
+	for {
+		line = strings.TrimLeft(line, " \t")
+		if line == "" {
+			return pairs, len(pairs) > 0
+		}
+
+		end := strings.IndexAny(line, "= \t")
+		if end == 0 || end > 0 && strings.ContainsRune(line[:end], '"') {
+			return nil, false
+		}
+		if end < 0 || line[end] != '=' {
+			if end < 0 {
+				end = len(line)
+			}
+			pairs = append(pairs, logfmtPair{key: line[:end]})
+			line = line[end:]
+			continue
+		}
+
+		key, rest := line[:end], line[end+1:]
+		var value string
+		quoted := strings.HasPrefix(rest, `"`)
+		if quoted {
+			prefix, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, false
+			}
+			value, _ = strconv.Unquote(prefix)
+			rest = rest[len(prefix):]
+			if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+				return nil, false
+			}
+		} else {
+			end := strings.IndexAny(rest, " \t")
+			if end < 0 {
+				end = len(rest)
+			}
+			value, rest = rest[:end], rest[end:]
+		}
+		pairs = append(pairs, logfmtPair{key: key, value: value, quoted: quoted})
+		line = rest
+	}
+}
@@ -1,7 +1,11 @@
 package logparser
 
 import (
+	"bufio"
+	"errors"
 	"io"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -22,6 +26,16 @@ type LogEntry struct {
 	Timestamp time.Time // The time when the log entry was created
 	Level     LogLevel  // Log level (DEBUG, INFO, WARNING, ERROR)
 	Message   string    // The actual log message content
+	// Fields are the structured attributes of the entry, other than the ones above.
+	// They are nil for formats without structure, like the default text one.
+	Fields Fields
+	// Source is the name of the source the entry was read from, set by WithName.
+	Source string
+	// Labels are the labels of the source set by WithLabels. The map is shared
+	// by all entries of the source and must not be modified.
+	Labels map[string]string
+	// Late is set on entries emitted out of order by the LateEmit policy.
+	Late bool
 }
 
 // LogReader provides functionality for reading and parsing log entries from multiple sources.
@@ -35,30 +49,310 @@ type LogEntry struct {
 // The timestamp must be in "2006-01-02 15:04:05" format
 // The log level must be one of: DEBU, INFO, WARN, ERRO
 // The message can contain any text and is separated from the level by a space.
+//
+// Entries of all sources are merged by timestamp: an entry is emitted only when
+// every source that hasn't ended has an entry ready, and the earliest of them goes first.
+// So a source that is slow to produce its next entry holds back the others,
+// unless WithAllowedLateness limits how long they wait.
+//
+// An entry older than an already emitted one, like one of a source added later
+// or one that came after its lateness window, is late. It is handled according
+// to WithLatePolicy, by default it is emitted with Late set.
 type LogReader struct {
+	out  chan LogEntry
+	late chan LogEntry
+	add  chan *source
+	done chan struct{}
+
+	// bounded is set if entries wait for silent sources at most lateness.
+	bounded    bool
+	lateness   time.Duration
+	latePolicy LatePolicy
+	// filter skips entries if set, see WithFilter.
+	filter func(LogEntry) bool
+
+	closeOnce sync.Once
+	merged    chan struct{}
+
+	mu      sync.Mutex
+	sources []*source
+	// closers are called by Close after the merger stops.
+	closers []func()
+}
+
+// sourceEvent is the next entry of a source, or the end of it if eof is set.
+type sourceEvent struct {
+	src   *source
+	entry LogEntry
+	eof   bool
+	// end is the number of bytes of the source read up to the end of the entry.
+	end int64
 }
 
+// ReaderOption configures a LogReader.
+type ReaderOption func(*LogReader)
+
 // NewLogReader creates and returns a new instance of LogReader.
 // The returned LogReader is ready to accept log sources through AddSource().
-func NewLogReader() *LogReader {
-	return nil
+func NewLogReader(opts ...ReaderOption) *LogReader {
+	lr := &LogReader{
+		out:    make(chan LogEntry),
+		late:   make(chan LogEntry),
+		add:    make(chan *source),
+		done:   make(chan struct{}),
+		merged: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(lr)
+	}
+	go lr.merge()
+	return lr
 }
 
 // AddSource adds a new log source to the reader.
 // Multiple sources can be added and will be processed concurrently.
 // The reader parameter should provide log entries in the expected format.
 // Invalid log entries will be skipped.
-func (lr *LogReader) AddSource(reader io.Reader) {
+//
+// Options like WithName and WithLabels identify the source in its entries
+// and in Sources.
+func (lr *LogReader) AddSource(reader io.Reader, opts ...SourceOption) {
+	lr.AddSourceWithParser(reader, TextParser{}, opts...)
+}
+
+// AddSourceWithParser adds a new log source to the reader, whose lines
+// are parsed by the parser. Lines the parser fails on are skipped.
+//
+// The parser is used only by the goroutine reading this source, so stateful
+// parsers like the one returned by AutoDetect must not be shared between sources.
+func (lr *LogReader) AddSourceWithParser(reader io.Reader, parser Parser, opts ...SourceOption) {
+	src := &source{reader: reader, parser: parser, credit: make(chan struct{}, 1)}
+	for _, opt := range opts {
+		opt(src)
+	}
+
+	lr.mu.Lock()
+	src.index = len(lr.sources)
+	lr.sources = append(lr.sources, src)
+	lr.mu.Unlock()
+
+	select {
+	case lr.add <- src:
+	case <-lr.done:
+	}
 }
 
 // Stream returns a receive-only channel of LogEntry.
 // The channel will receive parsed log entries from all added sources
 // in chronological order. The channel will be closed when Close() is called.
 func (lr *LogReader) Stream() <-chan LogEntry {
-	return nil
+	return lr.out
+}
+
+// Late returns the channel of late entries if the LateSideChannel policy is set.
+// Like Stream, it is closed when Close is called.
+func (lr *LogReader) Late() <-chan LogEntry {
+	return lr.late
 }
 
-// Close stops all reading operations and closes the output channel.
+// Close stops all reading operations and closes the output channels.
 // After calling Close(), no more log entries will be processed.
 func (lr *LogReader) Close() {
+	lr.closeOnce.Do(func() {
+		close(lr.done)
+		<-lr.merged
+		lr.mu.Lock()
+		for _, closer := range lr.closers {
+			closer()
+		}
+		lr.mu.Unlock()
+		close(lr.out)
+		close(lr.late)
+	})
+}
+
+// merge collects entries of all sources and sends them to the output
+// channel in the order of timestamps.
+func (lr *LogReader) merge() {
+	defer close(lr.merged)
+
+	events := make(chan sourceEvent)
+	// heads holds the next entry of every active source that has one.
+	active := make(map[*source]bool)
+	heads := make(map[*source]pendingEntry)
+	// watermark is the latest timestamp emitted, entries before it are late.
+	var watermark time.Time
+	// lateQueue holds late entries for the side channel. Their sources
+	// get the credit back only once they are taken, so it stays short.
+	var lateQueue []sourceEvent
+	var timer *time.Timer
+
+	for {
+		if timer != nil {
+			timer.Stop()
+			timer = nil
+		}
+		var (
+			out     chan LogEntry
+			next    LogEntry
+			nextEnd int64
+			from    *source
+			arrived time.Time
+			expired <-chan time.Time
+		)
+		for src, head := range heads {
+			if from == nil || head.entry.Timestamp.Before(next.Timestamp) ||
+				head.entry.Timestamp.Equal(next.Timestamp) && src.index < from.index {
+				next, nextEnd, from = head.entry, head.end, src
+			}
+			if arrived.IsZero() || head.arrived.Before(arrived) {
+				arrived = head.arrived
+			}
+		}
+		switch {
+		case from == nil:
+		// Sources with a late entry in lateQueue have no head, but can't
+		// send their next entry until it is taken, which may never happen.
+		case len(heads)+len(lateQueue) == len(active) || next.Late:
+			out = lr.out
+		case lr.bounded:
+			// The entry waiting the longest has waited enough,
+			// so the earliest one goes without the silent sources.
+			if wait := time.Until(arrived.Add(lr.lateness)); wait > 0 {
+				timer = time.NewTimer(wait)
+				expired = timer.C
+			} else {
+				out = lr.out
+			}
+		}
+
+		var (
+			lateOut  chan LogEntry
+			lateNext sourceEvent
+		)
+		if len(lateQueue) > 0 {
+			lateOut, lateNext = lr.late, lateQueue[0]
+		}
+
+		select {
+		case out <- next:
+			delete(heads, from)
+			if next.Timestamp.After(watermark) {
+				watermark = next.Timestamp
+			}
+			from.emitted(nextEnd)
+			from.credit <- struct{}{}
+		case lateOut <- lateNext.entry:
+			lateQueue = lateQueue[1:]
+			lateNext.src.consumed(lateNext.end)
+			lateNext.src.credit <- struct{}{}
+		case <-expired:
+		case ev := <-events:
+			if ev.eof {
+				delete(active, ev.src)
+				break
+			}
+			if ev.entry.Timestamp.Before(watermark) {
+				ev.src.late()
+				switch lr.latePolicy {
+				case LateDrop:
+					ev.src.consumed(ev.end)
+					ev.src.credit <- struct{}{}
+					continue
+				case LateSideChannel:
+					lateQueue = append(lateQueue, ev)
+					continue
+				}
+				ev.entry.Late = true
+			}
+			heads[ev.src] = pendingEntry{entry: ev.entry, end: ev.end, arrived: time.Now()}
+		case src := <-lr.add:
+			active[src] = true
+			src.credit <- struct{}{}
+			go lr.read(src, events)
+		case <-lr.done:
+			return
+		}
+	}
+}
+
+// pendingEntry is the next entry of a source waiting to be emitted.
+type pendingEntry struct {
+	entry   LogEntry
+	end     int64
+	arrived time.Time
+}
+
+// read parses lines of the source and sends them to the merger.
+func (lr *LogReader) read(src *source, events chan<- sourceEvent) {
+	send := func(ev sourceEvent) bool {
+		select {
+		case <-src.credit:
+		case <-lr.done:
+			return false
+		}
+		if !ev.eof {
+			src.sent()
+		}
+		select {
+		case events <- ev:
+			return true
+		case <-lr.done:
+			return false
+		}
+	}
+
+	// emit sends the entry ending at the position end of the source
+	// to the merger, unless the filter skips it. It returns false
+	// if the reader is closed.
+	emit := func(entry LogEntry, end int64) bool {
+		entry.Source, entry.Labels = src.name, src.labels
+		if lr.filter != nil && !lr.filter(entry) {
+			src.filtered()
+			src.skipped(end)
+			return true
+		}
+		return send(sourceEvent{src: src, entry: entry, end: end})
+	}
+
+	var err error
+	if src.multiline != nil {
+		err = lr.readMultiline(src, emit)
+	} else {
+		err = lr.readLines(src, emit)
+	}
+	// Read errors end the source just like EOF, but mark it as failed.
+	if err != nil {
+		src.finish(err)
+		send(sourceEvent{src: src, eof: true})
+	}
+}
+
+// readLines emits an entry for every line of the source that parses.
+// It returns the error that ended the source, or nil if the reader is closed.
+func (lr *LogReader) readLines(src *source, emit func(LogEntry, int64) bool) error {
+	r := bufio.NewReader(src.reader)
+	var end int64
+	for {
+		line, err := r.ReadString('\n')
+		if errors.Is(err, errLineBreak) {
+			err = nil
+		}
+		end += int64(len(line))
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			src.skipped(end)
+		} else {
+			entry, parseErr := src.parser.Parse(line)
+			src.parsed(parseErr == nil)
+			if parseErr != nil {
+				src.skipped(end)
+			} else if !emit(entry, end) {
+				return nil
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
 }
@@ -0,0 +1,257 @@
+package logparser
+
+import (
+	"bufio"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMultilineMaxLines     = 500
+	defaultMultilineMaxBytes     = 64 << 10
+	defaultMultilineFlushTimeout = time.Second
+)
+
+// ContinuationRule reports whether a line continues the entry before it,
+// like a line of a stack trace following the log line that reported it.
+type ContinuationRule func(line string) bool
+
+// Indented matches lines starting with a space or a tab, like the frames
+// of Java stack traces.
+func Indented() ContinuationRule {
+	return func(line string) bool {
+		return line == "" || line[0] == ' ' || line[0] == '\t'
+	}
+}
+
+// NotMatching matches lines the pattern doesn't match, like ones that
+// don't start with a timestamp.
+func NotMatching(pattern *regexp.Regexp) ContinuationRule {
+	return func(line string) bool {
+		return !pattern.MatchString(line)
+	}
+}
+
+// timestampPrefix matches lines starting with an ISO 8601 date and time.
+var timestampPrefix = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}`)
+
+// NoTimestamp matches lines that don't start with a date and time like
+// "2024-01-01 10:00:00" or "2024-01-01T10:00:00Z", such as the lines of
+// a Go panic written to the log.
+func NoTimestamp() ContinuationRule {
+	return NotMatching(timestampPrefix)
+}
+
+// AnyOf matches lines matched by any of the rules.
+func AnyOf(rules ...ContinuationRule) ContinuationRule {
+	return func(line string) bool {
+		for _, rule := range rules {
+			if rule(line) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MultilineOptions configure how lines of a source are joined into entries.
+type MultilineOptions struct {
+	// Continuation matches the lines appended to the message of the entry
+	// before them, separated by newlines. If nil, every line the parser fails
+	// on is a continuation.
+	Continuation ContinuationRule
+	// MaxLines is the maximum number of lines of an entry, including the
+	// first one, 500 if zero. Further continuation lines are skipped.
+	MaxLines int
+	// MaxBytes is the maximum length of the message of an entry, 64 KiB
+	// if zero. Continuation lines that don't fit are skipped.
+	MaxBytes int
+	// FlushTimeout is how long an entry waits for its continuation lines
+	// before it is emitted, 1s if zero. Entries are emitted without waiting
+	// once the next entry starts or the source ends.
+	FlushTimeout time.Duration
+}
+
+// WithMultiline joins lines of the source into multi-line entries, like an
+// error followed by its stack trace:
+//
+//	2024-01-01 10:00:00 ERRO request failed
+//	java.lang.NullPointerException
+//	    at com.example.Handler.handle(Handler.java:42)
+//
+// Continuation lines that don't follow an entry are skipped.
+func WithMultiline(opts MultilineOptions) SourceOption {
+	if opts.MaxLines <= 0 {
+		opts.MaxLines = defaultMultilineMaxLines
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = defaultMultilineMaxBytes
+	}
+	if opts.FlushTimeout <= 0 {
+		opts.FlushTimeout = defaultMultilineFlushTimeout
+	}
+	return func(s *source) {
+		s.multiline = &opts
+	}
+}
+
+// sourceLine is a line of a source, with the position of its end in the source
+// and the error that ended the source after it, if any.
+type sourceLine struct {
+	text string
+	end  int64
+	err  error
+}
+
+// multilineEntry is an entry collecting its continuation lines.
+type multilineEntry struct {
+	entry LogEntry
+	end   int64
+	lines int
+	// full is set once a continuation line was skipped, so that
+	// shorter lines after it aren't appended out of place.
+	full bool
+}
+
+// readMultiline is like readLines, but joins continuation lines with the entry
+// before them. Lines are read by another goroutine, so that an entry can be
+// emitted after FlushTimeout while the source blocks.
+func (lr *LogReader) readMultiline(src *source, emit func(LogEntry, int64) bool) error {
+	opts := src.multiline
+	lines := make(chan sourceLine)
+	go func() {
+		r := bufio.NewReader(src.reader)
+		var end int64
+		for {
+			line, err := r.ReadString('\n')
+			if errors.Is(err, errLineBreak) {
+				err = nil
+			}
+			end += int64(len(line))
+			select {
+			case lines <- sourceLine{text: strings.TrimRight(line, "\r\n"), end: end, err: err}:
+			case <-lr.done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var pending *multilineEntry
+	flush := func() bool {
+		if pending == nil {
+			return true
+		}
+		entry := pending.entry
+		entry.Message = strings.TrimRight(entry.Message, "\n")
+		end := pending.end
+		pending = nil
+		return emit(entry, end)
+	}
+
+	timer := time.NewTimer(opts.FlushTimeout)
+	timer.Stop()
+	for {
+		select {
+		case line := <-lines:
+			if !addMultilineLine(src, line, &pending, flush) {
+				return nil
+			}
+			if line.err != nil {
+				if !flush() {
+					return nil
+				}
+				return line.err
+			}
+			if pending != nil {
+				timer.Reset(opts.FlushTimeout)
+			}
+		case <-timer.C:
+			if !flush() {
+				return nil
+			}
+		case <-lr.done:
+			return nil
+		}
+	}
+}
+
+// addMultilineLine appends the line to the pending entry if it is a continuation,
+// or flushes the pending entry and starts a new one with the line otherwise.
+// It returns false if the reader is closed.
+func addMultilineLine(src *source, line sourceLine, pending **multilineEntry, flush func() bool) bool {
+	if line.text == "" && line.err != nil {
+		// The source ended with a newline.
+		return true
+	}
+	opts := src.multiline
+	// Blank lines count neither as lines nor as skipped ones,
+	// like in sources of single-line entries.
+	count := func(ok bool) {
+		if line.text != "" {
+			src.parsed(ok)
+		}
+	}
+	// Lines that don't make it into an entry are committed with
+	// the pending entry, or right away if there is none.
+	skip := func() {
+		if p := *pending; p != nil {
+			p.end = line.end
+		} else {
+			src.skipped(line.end)
+		}
+	}
+
+	var (
+		entry    LogEntry
+		parseErr error
+		isCont   bool
+	)
+	if opts.Continuation != nil {
+		isCont = opts.Continuation(line.text)
+	} else {
+		entry, parseErr = src.parser.Parse(line.text)
+		isCont = parseErr != nil
+	}
+
+	if isCont {
+		p := *pending
+		switch {
+		case p == nil:
+			count(false)
+			skip()
+		case p.full || p.lines >= opts.MaxLines || len(p.entry.Message)+1+len(line.text) > opts.MaxBytes:
+			p.full = true
+			count(false)
+			skip()
+		default:
+			p.entry.Message += "\n" + line.text
+			p.end = line.end
+			p.lines++
+			count(true)
+		}
+		return true
+	}
+
+	if line.text == "" {
+		skip()
+		return true
+	}
+	if opts.Continuation != nil {
+		entry, parseErr = src.parser.Parse(line.text)
+	}
+	count(parseErr == nil)
+	if parseErr != nil {
+		skip()
+		return true
+	}
+	if !flush() {
+		return false
+	}
+	*pending = &multilineEntry{entry: entry, end: line.end, lines: 1}
+	return true
+}
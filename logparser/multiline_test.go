@@ -0,0 +1,110 @@
+package logparser
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiline(t *testing.T) {
+	const javaTrace = `java.lang.NullPointerException: oops
+    at com.example.Handler.handle(Handler.java:42)
+    at com.example.Server.run(Server.java:7)`
+
+	tests := []struct {
+		name     string
+		opts     MultilineOptions
+		input    string
+		expected []string
+		skipped  int
+	}{
+		{
+			name: "unparsed_lines",
+			input: "stray line before any entry\n" +
+				"2024-01-01 10:00:00 ERRO request failed\n" + javaTrace + "\n" +
+				"2024-01-01 10:00:01 INFO next\n",
+			expected: []string{"request failed\n" + javaTrace, "next"},
+			skipped:  1,
+		},
+		{
+			name: "go_panic",
+			opts: MultilineOptions{Continuation: NoTimestamp()},
+			input: "2024-01-01 10:00:00 ERRO crashed\n" +
+				"panic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:5 +0x18\n\n" +
+				"2024-01-01 10:00:01 INFO restarted",
+			expected: []string{
+				"crashed\npanic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:5 +0x18",
+				"restarted",
+			},
+		},
+		{
+			name: "indented",
+			opts: MultilineOptions{Continuation: Indented()},
+			input: "2024-01-01 10:00:00 WARN config:\n  a: 1\n  b: 2\n" +
+				"not indented\n\t c: 3\n",
+			expected: []string{"config:\n  a: 1\n  b: 2\n\t c: 3"},
+			skipped:  1,
+		},
+		{
+			name: "any_of",
+			opts: MultilineOptions{Continuation: AnyOf(Indented(), func(line string) bool {
+				return strings.HasPrefix(line, "Caused by:")
+			})},
+			input:    "2024-01-01 10:00:00 ERRO failed\n  at a\nCaused by: io\n  at b\n",
+			expected: []string{"failed\n  at a\nCaused by: io\n  at b"},
+		},
+		{
+			name:     "max_lines",
+			opts:     MultilineOptions{MaxLines: 3},
+			input:    "2024-01-01 10:00:00 ERRO failed\n1\n2\n3\n4\n2024-01-01 10:00:01 INFO next\n5\n",
+			expected: []string{"failed\n1\n2", "next\n5"},
+			skipped:  2,
+		},
+		{
+			name:     "max_bytes",
+			opts:     MultilineOptions{MaxBytes: 12},
+			input:    "2024-01-01 10:00:00 ERRO failed\n12345\n1\n",
+			expected: []string{"failed\n12345"},
+			skipped:  1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			reader := NewLogReader()
+			defer reader.Close()
+			reader.AddSource(strings.NewReader(tc.input), WithMultiline(tc.opts))
+
+			require.Equal(t, tc.expected, messages(readExactlyN(t, reader.Stream(), len(tc.expected))))
+			require.Equal(t, tc.skipped, reader.Sources()[0].Skipped)
+		})
+	}
+}
+
+func TestMultilineFlushTimeout(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+
+	reader := NewLogReader(WithFilter(MustParseQuery(`message ~ "trace"`).Match))
+	defer reader.Close()
+	reader.AddSource(r, WithMultiline(MultilineOptions{FlushTimeout: 20 * time.Millisecond}))
+
+	_, err := w.Write([]byte("2024-01-01 10:00:00 ERRO failed\n"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("with a trace\n"))
+	require.NoError(t, err)
+
+	// The source blocks, but the entry is emitted after the timeout,
+	// and filtered as a whole.
+	entries := readExactlyN(t, reader.Stream(), 1)
+	require.Equal(t, "failed\nwith a trace", entries[0].Message)
+	require.Equal(t, LogLevel(LogLevelError), entries[0].Level)
+
+	_, err = w.Write([]byte("2024-01-01 10:00:01 INFO done\n"))
+	require.NoError(t, err)
+	readExactlyN(t, reader.Stream(), 0)
+	require.Equal(t, 1, reader.Sources()[0].Filtered)
+}
@@ -0,0 +1,141 @@
+package logparser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidLine is returned by parsers for lines not in their format.
+var ErrInvalidLine = errors.New("logparser: invalid line")
+
+// ErrUnknownFormat is returned by the parser of AutoDetect if none
+// of the first lines matched any known format.
+var ErrUnknownFormat = errors.New("logparser: unknown log format")
+
+// Parser parses a single line of a log, without the trailing newline,
+// into a LogEntry.
+type Parser interface {
+	Parse(line string) (LogEntry, error)
+}
+
+// ParserFunc is an adapter to use an ordinary function as a Parser.
+type ParserFunc func(line string) (LogEntry, error)
+
+// Parse calls f(line).
+func (f ParserFunc) Parse(line string) (LogEntry, error) {
+	return f(line)
+}
+
+const textTimeLayout = "2006-01-02 15:04:05"
+
+// TextParser parses lines in the default format of LogReader:
+//
+//	2024-01-01 10:00:00 INFO This is an info message
+//
+// The timestamp is in UTC and the level is one of DEBU, INFO, WARN, ERRO.
+type TextParser struct{}
+
+func (TextParser) Parse(line string) (LogEntry, error) {
+	if len(line) < len(textTimeLayout)+5 || line[len(textTimeLayout)] != ' ' {
+		return LogEntry{}, invalidLine("text", line)
+	}
+	ts, err := time.Parse(textTimeLayout, line[:len(textTimeLayout)])
+	if err != nil {
+		return LogEntry{}, invalidLine("text", line)
+	}
+
+	rest := line[len(textTimeLayout)+1:]
+	var level LogLevel
+	switch rest[:4] {
+	case "DEBU":
+		level = LogLevelDebug
+	case "INFO":
+		level = LogLevelInfo
+	case "WARN":
+		level = LogLevelWarn
+	case "ERRO":
+		level = LogLevelError
+	default:
+		return LogEntry{}, invalidLine("text", line)
+	}
+
+	message := rest[4:]
+	if message != "" {
+		if message[0] != ' ' {
+			return LogEntry{}, invalidLine("text", line)
+		}
+		message = message[1:]
+	}
+	return LogEntry{Timestamp: ts, Level: level, Message: message}, nil
+}
+
+// autoDetectLines is the number of first lines AutoDetect tries to recognize.
+const autoDetectLines = 10
+
+// AutoDetect returns a Parser that recognizes the format of a log by its first lines.
+//
+// Until a line is parsed successfully, every line is tried with JSONParser,
+// SyslogParser, CLFParser, TextParser and LogfmtParser in this order, and the first
+// one that succeeds is used for the rest of the log. If none of the first 10 lines
+// matched, all lines fail with ErrUnknownFormat.
+//
+// The returned parser is stateful, so every source needs its own.
+func AutoDetect() Parser {
+	return &autoDetector{}
+}
+
+type autoDetector struct {
+	parser Parser
+	tried  int
+}
+
+func (d *autoDetector) Parse(line string) (LogEntry, error) {
+	if d.parser != nil {
+		return d.parser.Parse(line)
+	}
+	if d.tried >= autoDetectLines {
+		return LogEntry{}, ErrUnknownFormat
+	}
+	d.tried++
+
+	for _, p := range []Parser{JSONParser{}, SyslogParser{}, CLFParser{}, TextParser{}, LogfmtParser{}} {
+		if entry, err := p.Parse(line); err == nil {
+			d.parser = p
+			return entry, nil
+		}
+	}
+	return LogEntry{}, invalidLine("any known format", line)
+}
+
+// parseLevel maps common names of log levels, like "warning", "err" or "crit",
+// to LogLevel, ignoring case.
+func parseLevel(s string) (LogLevel, bool) {
+	switch strings.ToLower(s) {
+	case "trace", "debug", "debu", "dbg":
+		return LogLevelDebug, true
+	case "info", "information", "informational", "notice":
+		return LogLevelInfo, true
+	case "warn", "warning":
+		return LogLevelWarn, true
+	case "error", "erro", "err", "fatal", "panic", "crit", "critical", "alert", "emerg", "emergency":
+		return LogLevelError, true
+	default:
+		return 0, false
+	}
+}
+
+// parseTimestamp parses RFC 3339 timestamps, with or without fractional seconds.
+func parseTimestamp(s string) (time.Time, bool) {
+	ts, err := time.Parse(time.RFC3339Nano, s)
+	return ts, err == nil
+}
+
+func invalidLine(format, line string) error {
+	const maxQuoted = 64
+	if len(line) > maxQuoted {
+		line = line[:maxQuoted] + "..."
+	}
+	return fmt.Errorf("%w: not in %s format: %q", ErrInvalidLine, format, line)
+}
@@ -0,0 +1,294 @@
+package logparser
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsers(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		parser   Parser
+		line     string
+		expected LogEntry
+		invalid  bool
+	}{
+		{
+			name:     "text",
+			parser:   TextParser{},
+			line:     "2024-01-01 10:00:00 WARN Disk is almost full",
+			expected: LogEntry{Timestamp: ts, Level: LogLevelWarn, Message: "Disk is almost full"},
+		},
+		{
+			name:     "text_empty_message",
+			parser:   TextParser{},
+			line:     "2024-01-01 10:00:00 INFO",
+			expected: LogEntry{Timestamp: ts, Level: LogLevelInfo},
+		},
+		{name: "text_bad_level", parser: TextParser{}, line: "2024-01-01 10:00:00 INFORMATION x", invalid: true},
+		{name: "text_bad_time", parser: TextParser{}, line: "2024-13-01 10:00:00 INFO x", invalid: true},
+		{
+			name:   "json",
+			parser: JSONParser{},
+			line:   `{"time":"2024-01-01T10:00:00Z","level":"ERROR","msg":"Connection refused","attempt":3}`,
+			expected: LogEntry{
+				Timestamp: ts, Level: LogLevelError, Message: "Connection refused",
+				Fields: Fields{{Key: "attempt", Value: IntValue(3)}},
+			},
+		},
+		{
+			name:     "json_unix_time",
+			parser:   JSONParser{},
+			line:     `{"ts":1704103200.5,"severity":"debug","message":"tick"}`,
+			expected: LogEntry{Timestamp: ts.Add(500 * time.Millisecond), Level: LogLevelDebug, Message: "tick"},
+		},
+		{
+			name:     "json_no_level",
+			parser:   JSONParser{},
+			line:     `{"@timestamp":"2024-01-01T12:00:00+02:00","message":"started"}`,
+			expected: LogEntry{Timestamp: ts.In(time.FixedZone("", 2*60*60)), Level: LogLevelInfo, Message: "started"},
+		},
+		{
+			name:   "json_fields",
+			parser: JSONParser{},
+			line: `{"time":"2024-01-01T10:00:00Z","msg":"done","http":{"status":200,"ok":true},` +
+				`"took":"250ms","ratio":0.5,"tags":["a", "b"],"parent":null,"at":"2024-01-01T09:00:00Z","id":"42"}`,
+			expected: LogEntry{Timestamp: ts, Level: LogLevelInfo, Message: "done", Fields: Fields{
+				{Key: "http.status", Value: IntValue(200)},
+				{Key: "http.ok", Value: BoolValue(true)},
+				{Key: "took", Value: DurationValue(250 * time.Millisecond)},
+				{Key: "ratio", Value: FloatValue(0.5)},
+				{Key: "tags", Value: StringValue(`["a", "b"]`)},
+				{Key: "at", Value: TimeValue(ts.Add(-time.Hour))},
+				{Key: "id", Value: StringValue("42")},
+			}},
+		},
+		{
+			name:     "json_numeric_level",
+			parser:   JSONParser{},
+			line:     `{"time":1704103200,"level":40,"msg":"slow","pid":7}`,
+			expected: LogEntry{Timestamp: ts, Level: LogLevelWarn, Message: "slow", Fields: Fields{{Key: "pid", Value: IntValue(7)}}},
+		},
+		{
+			name:   "json_pino",
+			parser: JSONParser{},
+			line:   `{"level":30,"time":1704103200123,"pid":7,"hostname":"web","msg":"request completed"}`,
+			expected: LogEntry{
+				Timestamp: ts.Add(123 * time.Millisecond), Level: LogLevelInfo, Message: "request completed",
+				Fields: Fields{{Key: "pid", Value: IntValue(7)}, {Key: "hostname", Value: StringValue("web")}},
+			},
+		},
+		{
+			name:     "json_unix_micro",
+			parser:   JSONParser{},
+			line:     `{"ts":1704103200000001,"msg":"x"}`,
+			expected: LogEntry{Timestamp: ts.Add(time.Microsecond), Level: LogLevelInfo, Message: "x"},
+		},
+		{
+			name:     "json_unix_nano",
+			parser:   JSONParser{},
+			line:     `{"ts":1704103200000000001,"msg":"x"}`,
+			expected: LogEntry{Timestamp: ts.Add(time.Nanosecond), Level: LogLevelInfo, Message: "x"},
+		},
+		{
+			name:     "json_unix_milli_float",
+			parser:   JSONParser{},
+			line:     `{"ts":1704103200500.0,"msg":"x"}`,
+			expected: LogEntry{Timestamp: ts.Add(500 * time.Millisecond), Level: LogLevelInfo, Message: "x"},
+		},
+		{name: "json_bad_numeric_level", parser: JSONParser{}, line: `{"time":1704103200,"level":35,"msg":"x"}`, invalid: true},
+		{name: "json_no_time", parser: JSONParser{}, line: `{"level":"info","msg":"x"}`, invalid: true},
+		{name: "json_broken", parser: JSONParser{}, line: `{"time":"2024-01-01T10:00:00Z"`, invalid: true},
+		{name: "json_trailing", parser: JSONParser{}, line: `{"time":"2024-01-01T10:00:00Z"} {}`, invalid: true},
+		{
+			name:   "logfmt",
+			parser: LogfmtParser{},
+			line:   `time=2024-01-01T10:00:00Z level=warning msg="Slow query \"users\"" duration=1.5s`,
+			expected: LogEntry{Timestamp: ts, Level: LogLevelWarn, Message: `Slow query "users"`, Fields: Fields{
+				{Key: "duration", Value: DurationValue(1500 * time.Millisecond)},
+			}},
+		},
+		{
+			name:   "logfmt_fields",
+			parser: LogfmtParser{},
+			line:   `ts=2024-01-01T10:00:00Z rows=12 ratio=0.25 cached=false id="42" debug`,
+			expected: LogEntry{Timestamp: ts, Level: LogLevelInfo, Fields: Fields{
+				{Key: "rows", Value: IntValue(12)},
+				{Key: "ratio", Value: FloatValue(0.25)},
+				{Key: "cached", Value: BoolValue(false)},
+				{Key: "id", Value: StringValue("42")},
+				{Key: "debug", Value: StringValue("")},
+			}},
+		},
+		{name: "logfmt_unterminated", parser: LogfmtParser{}, line: `time=2024-01-01T10:00:00Z msg="oops`, invalid: true},
+		{name: "logfmt_no_time", parser: LogfmtParser{}, line: `level=info msg=hello`, invalid: true},
+		{
+			name:   "syslog",
+			parser: SyslogParser{},
+			line:   `<165>1 2024-01-01T10:00:00Z host app 1234 ID47 [meta seq="1" note="a \"]\" b"][x@1 y="2" took="1.5s"] Config reloaded`,
+			expected: LogEntry{Timestamp: ts, Level: LogLevelInfo, Message: "Config reloaded", Fields: Fields{
+				{Key: "hostname", Value: StringValue("host")},
+				{Key: "app_name", Value: StringValue("app")},
+				{Key: "proc_id", Value: StringValue("1234")},
+				{Key: "msg_id", Value: StringValue("ID47")},
+				{Key: "meta.seq", Value: StringValue("1")},
+				{Key: "meta.note", Value: StringValue(`a "]" b`)},
+				{Key: "x@1.y", Value: StringValue("2")},
+				{Key: "x@1.took", Value: DurationValue(1500 * time.Millisecond)},
+			}},
+		},
+		{
+			name:     "syslog_no_structured_data",
+			parser:   SyslogParser{},
+			line:     "<11>1 2024-01-01T10:00:00Z - - - - - \uFEFFDisk failure",
+			expected: LogEntry{Timestamp: ts, Level: LogLevelError, Message: "Disk failure"},
+		},
+		{
+			name:   "syslog_no_message",
+			parser: SyslogParser{},
+			line:   "<15>1 2024-01-01T10:00:00Z host app - - -",
+			expected: LogEntry{Timestamp: ts, Level: LogLevelDebug, Fields: Fields{
+				{Key: "hostname", Value: StringValue("host")},
+				{Key: "app_name", Value: StringValue("app")},
+			}},
+		},
+		{name: "syslog_unterminated_param", parser: SyslogParser{}, line: `<14>1 2024-01-01T10:00:00Z h a - - [m k="v] x`, invalid: true},
+		{name: "syslog_bsd", parser: SyslogParser{}, line: "<34>Oct 11 22:14:15 mymachine su: 'su root' failed", invalid: true},
+		{name: "syslog_nil_time", parser: SyslogParser{}, line: "<34>1 - host app - - - msg", invalid: true},
+		{
+			name:   "clf",
+			parser: CLFParser{},
+			line:   `127.0.0.1 - frank [01/Jan/2024:10:00:00 +0000] "GET /index.html HTTP/1.1" 404 512`,
+			expected: LogEntry{Timestamp: ts, Level: LogLevelWarn, Message: `"GET /index.html HTTP/1.1" 404 512`, Fields: Fields{
+				{Key: "host", Value: StringValue("127.0.0.1")},
+				{Key: "user", Value: StringValue("frank")},
+				{Key: "method", Value: StringValue("GET")},
+				{Key: "path", Value: StringValue("/index.html")},
+				{Key: "protocol", Value: StringValue("HTTP/1.1")},
+				{Key: "status", Value: IntValue(404)},
+				{Key: "bytes", Value: IntValue(512)},
+			}},
+		},
+		{
+			name:   "clf_combined",
+			parser: CLFParser{},
+			line:   `10.0.0.1 - - [01/Jan/2024:13:00:00 +0300] "POST /api HTTP/2.0" 502 - "https://example.com/" "curl/8.0"`,
+			expected: LogEntry{
+				Timestamp: ts.In(time.FixedZone("", 3*60*60)),
+				Level:     LogLevelError,
+				Message:   `"POST /api HTTP/2.0" 502 - "https://example.com/" "curl/8.0"`,
+				Fields: Fields{
+					{Key: "host", Value: StringValue("10.0.0.1")},
+					{Key: "method", Value: StringValue("POST")},
+					{Key: "path", Value: StringValue("/api")},
+					{Key: "protocol", Value: StringValue("HTTP/2.0")},
+					{Key: "status", Value: IntValue(502)},
+					{Key: "referer", Value: StringValue("https://example.com/")},
+					{Key: "user_agent", Value: StringValue("curl/8.0")},
+				},
+			},
+		},
+		{name: "clf_bad_status", parser: CLFParser{}, line: `h - - [01/Jan/2024:10:00:00 +0000] "GET / HTTP/1.1" ok 1`, invalid: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			entry, err := tc.parser.Parse(tc.line)
+			if tc.invalid {
+				require.ErrorIs(t, err, ErrInvalidLine)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, tc.expected.Timestamp.Equal(entry.Timestamp), "timestamp %v", entry.Timestamp)
+			entry.Timestamp = tc.expected.Timestamp
+			require.Equal(t, tc.expected, entry)
+		})
+	}
+}
+
+func TestAutoDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected Parser
+	}{
+		{
+			name:     "json",
+			lines:    []string{"", `{"time":"2024-01-01T10:00:00Z","msg":"a"}`},
+			expected: JSONParser{},
+		},
+		{
+			name:     "syslog",
+			lines:    []string{"<14>1 2024-01-01T10:00:00Z h a - - - b"},
+			expected: SyslogParser{},
+		},
+		{
+			name:     "clf",
+			lines:    []string{`h - - [01/Jan/2024:10:00:00 +0000] "GET / HTTP/1.1" 200 1`},
+			expected: CLFParser{},
+		},
+		{
+			name:     "text",
+			lines:    []string{"# header", "2024-01-01 10:00:00 INFO a"},
+			expected: TextParser{},
+		},
+		{
+			name:     "logfmt",
+			lines:    []string{"ts=2024-01-01T10:00:00Z msg=a"},
+			expected: LogfmtParser{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := AutoDetect()
+			for _, line := range tc.lines[:len(tc.lines)-1] {
+				_, err := p.Parse(line)
+				require.ErrorIs(t, err, ErrInvalidLine)
+			}
+			_, err := p.Parse(tc.lines[len(tc.lines)-1])
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, p.(*autoDetector).parser)
+		})
+	}
+
+	p := AutoDetect()
+	for range autoDetectLines {
+		_, err := p.Parse("garbage")
+		require.ErrorIs(t, err, ErrInvalidLine)
+	}
+	_, err := p.Parse("2024-01-01 10:00:00 INFO a")
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestAddSourceWithParser(t *testing.T) {
+	jsonLog := strings.Join([]string{
+		`{"time":"2024-01-01T10:00:00Z","level":"info","msg":"json 1"}`,
+		`not json`,
+		`{"time":"2024-01-01T10:00:02Z","level":"error","msg":"json 2"}`,
+	}, "\n")
+	syslog := strings.Join([]string{
+		"<14>1 2024-01-01T10:00:01Z host app - - - syslog 1",
+		"<12>1 2024-01-01T10:00:03Z host app - - - syslog 2",
+	}, "\n")
+	access := `h - - [01/Jan/2024:10:00:04 +0000] "GET / HTTP/1.1" 500 1` + "\n"
+
+	reader := NewLogReader()
+	defer reader.Close()
+	reader.AddSourceWithParser(strings.NewReader(jsonLog), JSONParser{})
+	reader.AddSourceWithParser(strings.NewReader(syslog), SyslogParser{})
+	reader.AddSourceWithParser(strings.NewReader(access), AutoDetect())
+
+	entries := readExactlyN(t, reader.Stream(), 5)
+	var messages []string
+	for _, e := range entries {
+		messages = append(messages, e.Message)
+	}
+	require.Equal(t, []string{"json 1", "syslog 1", "json 2", "syslog 2", `"GET / HTTP/1.1" 500 1`}, messages)
+	require.Equal(t, LogLevel(LogLevelWarn), entries[3].Level)
+}
@@ -0,0 +1,310 @@
+package logparser
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidQuery is wrapped by the QueryError returned for malformed queries.
+var ErrInvalidQuery = errors.New("logparser: invalid query")
+
+// Query is a compiled filter of log entries, like:
+//
+//	level>=WARN AND message~"timeout" AND time in [10:00, 11:00)
+//
+// A query is made of comparisons joined with AND, OR and NOT, in the order
+// of precedence from the lowest, and grouped with parentheses. Keywords
+// are case-insensitive. A comparison has a subject on the left:
+//
+//   - level, compared with names of levels like WARN or error;
+//   - message or msg;
+//   - time, compared with RFC 3339 timestamps, dates like 2024-01-01,
+//     "2024-01-01 10:00:00" in UTC, or times of day like 10:00 or 10:00:30,
+//     which are compared with the time of day of entries;
+//   - source, the name of the source of entries;
+//   - labels.NAME, a label of the source;
+//   - any other name is a key of Fields, like http.status.
+//
+// The operators are =, !=, <, <=, >, >=, ~ and !~, which match a regular
+// expression against the value formatted as text. "x in [a, b)" is short
+// for "x >= a AND x < b", square brackets include the bound and parentheses
+// exclude it.
+//
+// Values are words or double-quoted strings, in which \" and \\ are the only
+// escapes. They are interpreted according to the type of the field they are
+// compared with, so "status >= 500" compares numbers and "took > 1s" compares
+// durations. A comparison with a missing field, or with a value that can't be
+// converted to the type of the field, doesn't match.
+type Query struct {
+	src  string
+	root queryNode
+}
+
+// ParseQuery compiles the query. Errors are *QueryError, which tell the column
+// of the mistake.
+func ParseQuery(query string) (*Query, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{src: query, tokens: tokens}
+	root, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	return &Query{src: query, root: root}, nil
+}
+
+// MustParseQuery is like ParseQuery but panics if the query is malformed.
+func MustParseQuery(query string) *Query {
+	q, err := ParseQuery(query)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Match reports whether the entry satisfies the query.
+func (q *Query) Match(entry LogEntry) bool {
+	return q.root.match(entry)
+}
+
+// String returns the source of the query.
+func (q *Query) String() string {
+	return q.src
+}
+
+// WithFilter makes LogReader skip entries for which keep returns false,
+// like Query.Match:
+//
+//	NewLogReader(WithFilter(MustParseQuery(`level>=WARN`).Match))
+//
+// Entries are filtered by the goroutines reading the sources before merging,
+// so skipped entries neither hold back other sources nor count as late.
+func WithFilter(keep func(LogEntry) bool) ReaderOption {
+	return func(lr *LogReader) {
+		lr.filter = keep
+	}
+}
+
+type queryNode interface {
+	match(entry LogEntry) bool
+}
+
+type andNode struct {
+	left, right queryNode
+}
+
+func (n andNode) match(entry LogEntry) bool {
+	return n.left.match(entry) && n.right.match(entry)
+}
+
+type orNode struct {
+	left, right queryNode
+}
+
+func (n orNode) match(entry LogEntry) bool {
+	return n.left.match(entry) || n.right.match(entry)
+}
+
+type notNode struct {
+	node queryNode
+}
+
+func (n notNode) match(entry LogEntry) bool {
+	return !n.node.match(entry)
+}
+
+type subjectKind int
+
+const (
+	subjectField subjectKind = iota
+	subjectLevel
+	subjectMessage
+	subjectTime
+	subjectSource
+	subjectLabel
+)
+
+// querySubject is what a comparison looks at in an entry.
+type querySubject struct {
+	kind subjectKind
+	name string
+	// key is the key of the field or label.
+	key string
+}
+
+func parseQuerySubject(name string) querySubject {
+	kind := subjectField
+	switch strings.ToLower(name) {
+	case "level":
+		kind = subjectLevel
+	case "message", "msg":
+		kind = subjectMessage
+	case "time":
+		kind = subjectTime
+	case "source":
+		kind = subjectSource
+	default:
+		// Like the other subjects the prefix ignores case, but label keys don't.
+		const prefix = "labels."
+		if len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			return querySubject{kind: subjectLabel, name: name, key: name[len(prefix):]}
+		}
+	}
+	return querySubject{kind: kind, name: name, key: name}
+}
+
+func (s querySubject) value(entry LogEntry) (Value, bool) {
+	switch s.kind {
+	case subjectLevel:
+		return IntValue(int64(entry.Level)), true
+	case subjectMessage:
+		return StringValue(entry.Message), true
+	case subjectTime:
+		return TimeValue(entry.Timestamp), true
+	case subjectSource:
+		return StringValue(entry.Source), true
+	case subjectLabel:
+		label, ok := entry.Labels[s.key]
+		return StringValue(label), ok
+	default:
+		return entry.Fields.Get(s.key)
+	}
+}
+
+type predicateNode struct {
+	subject querySubject
+	op      string
+	literal queryLiteral
+	// regexp is set for the ~ and !~ operators.
+	regexp *regexp.Regexp
+}
+
+func (n predicateNode) match(entry LogEntry) bool {
+	v, ok := n.subject.value(entry)
+	if !ok {
+		return false
+	}
+	if n.regexp != nil {
+		return n.regexp.MatchString(v.String()) == (n.op == "~")
+	}
+
+	c, ok := n.literal.compare(v)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// queryLiteral is a value of a query with all the types it can be read as.
+type queryLiteral struct {
+	text string
+
+	isInt, isNum, isBool, isDuration, isTime bool
+
+	int      int64
+	num      float64
+	bool     bool
+	duration time.Duration
+	time     time.Time
+	// clock is set if the literal is a time of day, held by duration.
+	clock bool
+}
+
+var (
+	queryTimeLayouts  = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+	queryClockLayouts = []string{"15:04", "15:04:05", "15:04:05.999999999"}
+)
+
+func parseQueryLiteral(text string) queryLiteral {
+	lit := queryLiteral{text: text}
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		lit.isInt, lit.int = true, i
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil && !math.IsNaN(f) {
+		lit.isNum, lit.num = true, f
+	}
+	if b, err := strconv.ParseBool(text); err == nil {
+		lit.isBool, lit.bool = true, b
+	}
+	if d, err := time.ParseDuration(text); err == nil {
+		lit.isDuration, lit.duration = true, d
+	}
+	for _, layout := range queryTimeLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			lit.isTime, lit.time = true, t
+			return lit
+		}
+	}
+	for _, layout := range queryClockLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			lit.isTime, lit.clock, lit.duration = true, true, timeOfDay(t)
+			return lit
+		}
+	}
+	return lit
+}
+
+// compare compares v with the literal read as the type of v.
+// The second value is false if the literal isn't of that type.
+func (lit queryLiteral) compare(v Value) (int, bool) {
+	switch v.Kind() {
+	case KindInt:
+		i, _ := v.Int()
+		if lit.isInt {
+			return cmp.Compare(i, lit.int), true
+		}
+		return cmp.Compare(float64(i), lit.num), lit.isNum
+	case KindFloat:
+		f, _ := v.Float()
+		return cmp.Compare(f, lit.num), lit.isNum
+	case KindBool:
+		b, _ := v.Bool()
+		return cmp.Compare(boolInt(b), boolInt(lit.bool)), lit.isBool
+	case KindDuration:
+		d, _ := v.Duration()
+		return cmp.Compare(d, lit.duration), lit.isDuration && !lit.clock
+	case KindTime:
+		t, _ := v.Time()
+		if lit.clock {
+			return cmp.Compare(timeOfDay(t), lit.duration), true
+		}
+		return t.Compare(lit.time), lit.isTime
+	default:
+		s, _ := v.Str()
+		return strings.Compare(s, lit.text), true
+	}
+}
+
+// timeOfDay returns the time elapsed since the midnight of t in its location.
+func timeOfDay(t time.Time) time.Duration {
+	h, m, s := t.Clock()
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute +
+		time.Duration(s)*time.Second + time.Duration(t.Nanosecond())
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
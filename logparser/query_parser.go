@@ -0,0 +1,344 @@
+package logparser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type queryTokenKind int
+
+const (
+	tokEOF queryTokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokComma
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	// offset is the byte offset of the token in the query.
+	offset int
+}
+
+func (t queryToken) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokString:
+		return fmt.Sprintf("string %q", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// keyword reports whether the token is the word kw, ignoring case.
+func (t queryToken) keyword(kw string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, kw)
+}
+
+// queryDelimiters end words, besides spaces.
+const queryDelimiters = `()[],"=!<>~`
+
+var queryPunctuation = map[byte]queryTokenKind{
+	'(': tokLParen,
+	')': tokRParen,
+	'[': tokLBracket,
+	']': tokRBracket,
+	',': tokComma,
+}
+
+// lexQuery splits the query into tokens, ending with tokEOF.
+func lexQuery(src string) ([]queryToken, error) {
+	var tokens []queryToken
+	for i := 0; ; {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case i == len(src):
+			return append(tokens, queryToken{kind: tokEOF, offset: i}), nil
+		case unicode.IsSpace(r):
+			i += size
+		case queryPunctuation[src[i]] != tokEOF:
+			tokens = append(tokens, queryToken{kind: queryPunctuation[src[i]], text: src[i : i+1], offset: i})
+			i++
+		case src[i] == '"':
+			text, end, ok := lexQueryString(src, i)
+			if !ok {
+				return nil, queryError(src, i, "unterminated string")
+			}
+			tokens = append(tokens, queryToken{kind: tokString, text: text, offset: i})
+			i = end
+		case strings.IndexByte("=!<>~", src[i]) >= 0:
+			op := src[i : i+1]
+			if rest := src[i+1:]; strings.IndexByte("!<>", src[i]) >= 0 && strings.HasPrefix(rest, "=") ||
+				src[i] == '!' && strings.HasPrefix(rest, "~") {
+				op = src[i : i+2]
+			}
+			if op == "!" {
+				return nil, queryError(src, i, `unexpected "!", use NOT or !=`)
+			}
+			tokens = append(tokens, queryToken{kind: tokOp, text: op, offset: i})
+			i += len(op)
+		default:
+			end := i
+			for end < len(src) {
+				r, size := utf8.DecodeRuneInString(src[end:])
+				if unicode.IsSpace(r) || strings.ContainsRune(queryDelimiters, r) {
+					break
+				}
+				end += size
+			}
+			tokens = append(tokens, queryToken{kind: tokWord, text: src[i:end], offset: i})
+			i = end
+		}
+	}
+}
+
+// lexQueryString reads the double-quoted string starting at src[start].
+// Only \" and \\ are escapes, other backslashes are kept, so regular
+// expressions can be written as they are.
+func lexQueryString(src string, start int) (text string, end int, ok bool) {
+	var b strings.Builder
+	for i := start + 1; i < len(src); i++ {
+		switch {
+		case src[i] == '"':
+			return b.String(), i + 1, true
+		case src[i] == '\\' && i+1 < len(src) && (src[i+1] == '"' || src[i+1] == '\\'):
+			i++
+		}
+		b.WriteByte(src[i])
+	}
+	return "", 0, false
+}
+
+// queryParser is a recursive descent parser of the grammar:
+//
+//	query      = or
+//	or         = and { "OR" and }
+//	and        = unary { "AND" unary }
+//	unary      = "NOT" unary | "(" or ")" | predicate
+//	predicate  = subject op value | subject "IN" ( "[" | "(" ) value "," value ( "]" | ")" )
+//	op         = "=" | "!=" | "<" | "<=" | ">" | ">=" | "~" | "!~"
+type queryParser struct {
+	src    string
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *queryParser) errorf(t queryToken, format string, args ...any) error {
+	return queryError(p.src, t.offset, fmt.Sprintf(format, args...))
+}
+
+func (p *queryParser) parseQuery() (queryNode, error) {
+	if p.peek().kind == tokEOF {
+		return nil, p.errorf(p.peek(), "empty query")
+	}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "unexpected %v, expected AND or OR", t)
+	}
+	return node, nil
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().keyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().keyword("AND") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	switch t := p.peek(); {
+	case t.keyword("NOT"):
+		p.next()
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	case t.kind == tokLParen:
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokRParen {
+			return nil, p.errorf(t, `unexpected %v, expected ")"`, t)
+		}
+		return node, nil
+	default:
+		return p.parsePredicate()
+	}
+}
+
+func (p *queryParser) parsePredicate() (queryNode, error) {
+	t := p.next()
+	if t.kind != tokWord || t.keyword("AND") || t.keyword("OR") || t.keyword("IN") {
+		return nil, p.errorf(t, "unexpected %v, expected a field name", t)
+	}
+	subj := parseQuerySubject(t.text)
+
+	op := p.next()
+	if op.keyword("IN") {
+		return p.parseRange(subj)
+	}
+	if op.kind != tokOp {
+		return nil, p.errorf(op, "unexpected %v, expected an operator after %q", op, t.text)
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return p.predicate(subj, op, value)
+}
+
+// parseRange parses the interval after "IN" as two comparisons.
+func (p *queryParser) parseRange(subj querySubject) (queryNode, error) {
+	open := p.next()
+	lowOp := queryToken{kind: tokOp, text: ">=", offset: open.offset}
+	switch open.kind {
+	case tokLBracket:
+	case tokLParen:
+		lowOp.text = ">"
+	default:
+		return nil, p.errorf(open, `unexpected %v, expected "[" or "("`, open)
+	}
+	low, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.next(); t.kind != tokComma {
+		return nil, p.errorf(t, `unexpected %v, expected ","`, t)
+	}
+	high, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	closing := p.next()
+	highOp := queryToken{kind: tokOp, text: "<=", offset: closing.offset}
+	switch closing.kind {
+	case tokRBracket:
+	case tokRParen:
+		highOp.text = "<"
+	default:
+		return nil, p.errorf(closing, `unexpected %v, expected "]" or ")"`, closing)
+	}
+
+	left, err := p.predicate(subj, lowOp, low)
+	if err != nil {
+		return nil, err
+	}
+	right, err := p.predicate(subj, highOp, high)
+	if err != nil {
+		return nil, err
+	}
+	return andNode{left, right}, nil
+}
+
+func (p *queryParser) parseValue() (queryToken, error) {
+	t := p.next()
+	if t.kind != tokWord && t.kind != tokString {
+		return queryToken{}, p.errorf(t, "unexpected %v, expected a value", t)
+	}
+	return t, nil
+}
+
+// predicate compiles the comparison of the subject with the value,
+// checking the value makes sense for the subject.
+func (p *queryParser) predicate(subj querySubject, op, value queryToken) (queryNode, error) {
+	pred := predicateNode{subject: subj, op: op.text, literal: parseQueryLiteral(value.text)}
+
+	if op.text == "~" || op.text == "!~" {
+		if subj.kind == subjectLevel || subj.kind == subjectTime {
+			return nil, p.errorf(op, "operator %s is not supported for %s", op.text, subj.name)
+		}
+		re, err := regexp.Compile(value.text)
+		if err != nil {
+			return nil, p.errorf(value, "invalid regular expression: %v", err)
+		}
+		pred.regexp = re
+		return pred, nil
+	}
+
+	switch subj.kind {
+	case subjectLevel:
+		level, ok := parseLevel(value.text)
+		if !ok {
+			return nil, p.errorf(value, "unknown level %q", value.text)
+		}
+		pred.literal = queryLiteral{text: value.text, isInt: true, isNum: true, int: int64(level), num: float64(level)}
+	case subjectTime:
+		if !pred.literal.isTime {
+			return nil, p.errorf(value, "invalid time %q", value.text)
+		}
+	}
+	return pred, nil
+}
+
+// QueryError is returned by ParseQuery for malformed queries.
+type QueryError struct {
+	// Column is the position of the mistake in the query, in runes starting from 1.
+	Column int
+	Msg    string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("logparser: invalid query at column %d: %s", e.Column, e.Msg)
+}
+
+// Unwrap returns ErrInvalidQuery.
+func (e *QueryError) Unwrap() error {
+	return ErrInvalidQuery
+}
+
+func queryError(src string, offset int, msg string) *QueryError {
+	return &QueryError{Column: utf8.RuneCountInString(src[:offset]) + 1, Msg: msg}
+}
@@ -0,0 +1,137 @@
+package logparser
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryMatch(t *testing.T) {
+	entry := LogEntry{
+		Timestamp: time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC),
+		Level:     LogLevelWarn,
+		Message:   `upstream timeout after "3" retries`,
+		Source:    "nginx",
+		Labels:    map[string]string{"host": "web-1"},
+		Fields: Fields{
+			{Key: "http.status", Value: IntValue(504)},
+			{Key: "ratio", Value: FloatValue(0.75)},
+			{Key: "took", Value: DurationValue(1500 * time.Millisecond)},
+			{Key: "cached", Value: BoolValue(false)},
+			{Key: "user", Value: StringValue("alice")},
+			{Key: "at", Value: TimeValue(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected bool
+	}{
+		{name: "level", query: "level>=WARN", expected: true},
+		{name: "level_lowercase", query: "level > warning", expected: false},
+		{name: "level_range", query: "level in [INFO, ERROR)", expected: true},
+		{name: "message_regexp", query: `message~"time(out)?"`, expected: true},
+		{name: "message_not_regexp", query: `msg !~ "\d+"`, expected: false},
+		{name: "message_escaped_quote", query: `message = "upstream timeout after \"3\" retries"`, expected: true},
+		{name: "time_of_day", query: "time in [10:00, 11:00)", expected: true},
+		{name: "time_of_day_excluded", query: "time in [10:00, 10:30)", expected: false},
+		{name: "time_date", query: "time >= 2024-01-01 AND time < 2024-01-02", expected: true},
+		{name: "time_rfc3339", query: "time = 2024-01-01T12:30:00+02:00", expected: true},
+		{name: "time_quoted", query: `time > "2024-01-01 10:30:00"`, expected: false},
+		{name: "source", query: "source = nginx", expected: true},
+		{name: "label", query: "labels.host = web-1", expected: true},
+		{name: "missing_label", query: "labels.dc != eu", expected: false},
+		{name: "label_prefix_case", query: "LABELS.host = web-1", expected: true},
+		{name: "label_key_case", query: "Labels.HOST = web-1", expected: false},
+		{name: "int_field", query: "http.status >= 500", expected: true},
+		{name: "int_field_float", query: "http.status < 504.5", expected: true},
+		{name: "float_field", query: "ratio in (0.5, 1]", expected: true},
+		{name: "duration_field", query: "took > 1s", expected: true},
+		{name: "bool_field", query: "cached = false", expected: true},
+		{name: "string_field", query: `user = "alice"`, expected: true},
+		{name: "time_field", query: "at < 2024-01-01T10:00:00Z", expected: true},
+		{name: "regexp_on_number", query: `http.status ~ "^5"`, expected: true},
+		{name: "type_mismatch", query: "http.status = abc", expected: false},
+		{name: "missing_field", query: "missing != 1", expected: false},
+		{name: "not", query: "NOT missing = 1", expected: true},
+		{name: "precedence", query: "level = ERROR AND user = bob OR source = nginx", expected: true},
+		{name: "parentheses", query: "level = ERROR AND (user = bob OR source = nginx)", expected: false},
+		{name: "keywords_case", query: "level = warn and not (user = bob or took < 1s)", expected: true},
+		{
+			name:     "example",
+			query:    `level>=WARN AND message~"timeout" AND time in [10:00, 11:00)`,
+			expected: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := ParseQuery(tc.query)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, q.Match(entry))
+			require.Equal(t, tc.query, q.String())
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		column int
+		msg    string
+	}{
+		{name: "empty", query: "  ", column: 3, msg: "empty query"},
+		{name: "missing_operator", query: "level WARN", column: 7, msg: `unexpected "WARN", expected an operator after "level"`},
+		{name: "missing_value", query: "level >=", column: 9, msg: "unexpected end of query, expected a value"},
+		{name: "unknown_level", query: "level >= LOUD", column: 10, msg: `unknown level "LOUD"`},
+		{name: "invalid_time", query: "time > noon", column: 8, msg: `invalid time "noon"`},
+		{name: "invalid_regexp", query: `msg ~ "(" OR a = 1`, column: 7, msg: "invalid regular expression: error parsing regexp: missing closing ): `(`"},
+		{name: "regexp_on_level", query: `level ~ "W"`, column: 7, msg: "operator ~ is not supported for level"},
+		{name: "unterminated_string", query: `msg = "oops`, column: 7, msg: "unterminated string"},
+		{name: "bang", query: "! a = 1", column: 1, msg: `unexpected "!", use NOT or !=`},
+		{name: "unclosed_paren", query: "(a = 1 OR b = 2", column: 16, msg: `unexpected end of query, expected ")"`},
+		{name: "bad_range_open", query: "a in 1, 2)", column: 6, msg: `unexpected "1", expected "[" or "("`},
+		{name: "bad_range_comma", query: "a in [1 2)", column: 9, msg: `unexpected "2", expected ","`},
+		{name: "bad_range_close", query: "a in [1, 2", column: 11, msg: `unexpected end of query, expected "]" or ")"`},
+		{name: "trailing", query: "a = 1 b = 2", column: 7, msg: `unexpected "b", expected AND or OR`},
+		{name: "keyword_as_field", query: "a = 1 AND OR b = 2", column: 11, msg: `unexpected "OR", expected a field name`},
+		{name: "unicode_column", query: `msg = "ключ" AND`, column: 17, msg: "unexpected end of query, expected a field name"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseQuery(tc.query)
+			require.ErrorIs(t, err, ErrInvalidQuery)
+			var qerr *QueryError
+			require.ErrorAs(t, err, &qerr)
+			require.Equal(t, &QueryError{Column: tc.column, Msg: tc.msg}, qerr)
+		})
+	}
+
+	require.Panics(t, func() { MustParseQuery("level") })
+}
+
+func TestReaderFilter(t *testing.T) {
+	slow := strings.Join([]string{
+		"2024-01-01 10:00:00 INFO started",
+		"2024-01-01 10:00:01 ERRO connection timeout",
+		"2024-01-01 10:00:02 DEBU tick",
+	}, "\n")
+	// Filtered entries of the second source must not hold back the first one.
+	noisy := strings.Repeat("2024-01-01 09:00:00 DEBU noise\n", 3) + "2024-01-01 10:00:03 WARN disk timeout\n"
+
+	reader := NewLogReader(WithFilter(MustParseQuery(`level >= WARN AND message ~ "timeout"`).Match))
+	defer reader.Close()
+	reader.AddSource(strings.NewReader(slow))
+	reader.AddSource(strings.NewReader(noisy))
+
+	entries := readExactlyN(t, reader.Stream(), 2)
+	require.Equal(t, "connection timeout", entries[0].Message)
+	require.Equal(t, "disk timeout", entries[1].Message)
+	require.Equal(t, 3, reader.Sources()[1].Filtered)
+	require.Zero(t, reader.Sources()[1].Late)
+}
@@ -0,0 +1,193 @@
+package logparser
+
+import (
+	"errors"
+	"io"
+	"maps"
+	"strconv"
+	"sync"
+)
+
+// source is a log source read by its own goroutine, which hands
+// parsed entries to the merger one at a time.
+type source struct {
+	reader io.Reader
+	parser Parser
+	name   string
+	labels map[string]string
+	// index is the position of the source in the order of adding,
+	// which breaks ties between entries with equal timestamps.
+	index int
+	// credit allows the source to send its next entry, the merger
+	// returns it after taking the previous one.
+	credit chan struct{}
+	// multiline joins continuation lines with entries if set.
+	multiline *MultilineOptions
+	// commit, if set, is told how many bytes of the source were read
+	// up to the end of the last entry that left the reader, or of the
+	// lines after it that produced no entry.
+	commit func(end int64)
+
+	mu    sync.Mutex
+	stats SourceInfo
+	// inflight is set while an entry of the source is in the merger,
+	// and skippedEnd is the end of the lines skipped after it, which
+	// are committed with it.
+	inflight   bool
+	skippedEnd int64
+}
+
+// SourceOption configures a source added to LogReader.
+type SourceOption func(*source)
+
+// WithName names the source. The name is set as Source of its entries.
+func WithName(name string) SourceOption {
+	return func(s *source) {
+		s.name = name
+	}
+}
+
+// WithLabels attaches labels, like the host or service that wrote the log,
+// to the source. They are set as Labels of its entries. The map is copied,
+// and options given more than once are merged.
+func WithLabels(labels map[string]string) SourceOption {
+	return func(s *source) {
+		if s.labels == nil {
+			s.labels = make(map[string]string, len(labels))
+		}
+		maps.Copy(s.labels, labels)
+	}
+}
+
+// SourceStatus is the state of reading a source.
+type SourceStatus int
+
+const (
+	// SourceReading means the source may have more lines.
+	SourceReading SourceStatus = iota
+	// SourceEOF means all lines of the source were read.
+	SourceEOF
+	// SourceFailed means reading the source failed with an error other than io.EOF.
+	SourceFailed
+)
+
+var sourceStatusNames = [...]string{"reading", "EOF", "failed"}
+
+func (s SourceStatus) String() string {
+	if s >= 0 && int(s) < len(sourceStatusNames) {
+		return sourceStatusNames[s]
+	}
+	return "SourceStatus(" + strconv.Itoa(int(s)) + ")"
+}
+
+// SourceInfo describes a source of LogReader and its progress.
+type SourceInfo struct {
+	Name   string
+	Labels map[string]string
+	Status SourceStatus
+	// Err is the error reading failed with if Status is SourceFailed.
+	Err error
+
+	// Lines is the number of non-empty lines read from the source,
+	// of which Skipped failed to parse and Filtered were parsed
+	// but rejected by WithFilter.
+	Lines    int
+	Skipped  int
+	Filtered int
+	// Emitted is the number of entries of the source sent to Stream.
+	Emitted int
+	// Late is the number of late entries of the source, whatever
+	// the LatePolicy did with them.
+	Late int
+}
+
+// Sources returns the sources of the reader in the order they were added.
+func (lr *LogReader) Sources() []SourceInfo {
+	lr.mu.Lock()
+	sources := lr.sources
+	lr.mu.Unlock()
+
+	infos := make([]SourceInfo, len(sources))
+	for i, src := range sources {
+		src.mu.Lock()
+		infos[i] = src.stats
+		src.mu.Unlock()
+		infos[i].Name, infos[i].Labels = src.name, maps.Clone(src.labels)
+	}
+	return infos
+}
+
+func (s *source) parsed(ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Lines++
+	if !ok {
+		s.stats.Skipped++
+	}
+}
+
+func (s *source) filtered() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Filtered++
+}
+
+func (s *source) emitted(end int64) {
+	s.mu.Lock()
+	s.stats.Emitted++
+	s.mu.Unlock()
+	s.consumed(end)
+}
+
+// sent reports that an entry was handed to the merger.
+func (s *source) sent() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inflight = true
+}
+
+// consumed reports that the entry ending at end left the reader.
+func (s *source) consumed(end int64) {
+	if s.commit == nil {
+		return
+	}
+	s.mu.Lock()
+	s.inflight = false
+	end = max(end, s.skippedEnd)
+	s.mu.Unlock()
+	s.commit(end)
+}
+
+// skipped reports that the lines up to end produced no entry, because they
+// failed to parse or were filtered. They are committed right away, or with
+// the entry before them if it is still in the merger.
+func (s *source) skipped(end int64) {
+	if s.commit == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.inflight {
+		s.skippedEnd = end
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+	s.commit(end)
+}
+
+func (s *source) late() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Late++
+}
+
+// finish records the end of the source, caused by err.
+func (s *source) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if errors.Is(err, io.EOF) {
+		s.stats.Status = SourceEOF
+	} else {
+		s.stats.Status, s.stats.Err = SourceFailed, err
+	}
+}
@@ -0,0 +1,85 @@
+package logparser
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceIdentity(t *testing.T) {
+	labels := map[string]string{"host": "web-1"}
+
+	reader := NewLogReader()
+	defer reader.Close()
+	reader.AddSource(strings.NewReader("2024-01-01 10:00:00 INFO first\n"),
+		WithName("app.log"), WithLabels(labels), WithLabels(map[string]string{"service": "api"}))
+	reader.AddSource(strings.NewReader("2024-01-01 10:00:01 INFO second\n"))
+	labels["host"] = "changed"
+
+	entries := readExactlyN(t, reader.Stream(), 2)
+	require.Equal(t, "app.log", entries[0].Source)
+	require.Equal(t, map[string]string{"host": "web-1", "service": "api"}, entries[0].Labels)
+	require.Empty(t, entries[1].Source)
+	require.Nil(t, entries[1].Labels)
+}
+
+func TestSources(t *testing.T) {
+	failing := io.MultiReader(
+		strings.NewReader("2024-01-01 10:00:01 INFO from failing\n"),
+		&errorReader{err: errors.New("disk on fire")},
+	)
+	pending, w := io.Pipe()
+	defer w.Close()
+
+	reader := NewLogReader()
+	defer reader.Close()
+	reader.AddSource(strings.NewReader("2024-01-01 10:00:00 INFO a\ngarbage\n2024-01-01 10:00:02 INFO b\n"),
+		WithName("done"))
+	reader.AddSource(failing, WithName("failing"), WithLabels(map[string]string{"kind": "broken"}))
+	reader.AddSource(pending, WithName("pending"))
+
+	_, err := w.Write([]byte("2024-01-01 10:00:03 INFO c\n"))
+	require.NoError(t, err)
+
+	entries := readExactlyN(t, reader.Stream(), 4)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Source)
+	}
+	require.Equal(t, []string{"done", "failing", "done", "pending"}, names)
+
+	sources := reader.Sources()
+	require.Len(t, sources, 3)
+	require.Equal(t, SourceInfo{Name: "done", Status: SourceEOF, Lines: 3, Skipped: 1, Emitted: 2}, sources[0])
+
+	require.Equal(t, SourceFailed, sources[1].Status)
+	require.EqualError(t, sources[1].Err, "disk on fire")
+	require.Equal(t, map[string]string{"kind": "broken"}, sources[1].Labels)
+	require.Equal(t, 1, sources[1].Emitted)
+
+	require.Equal(t, SourceInfo{Name: "pending", Status: SourceReading, Lines: 1, Emitted: 1}, sources[2])
+
+	require.NoError(t, w.Close())
+	require.Eventually(t, func() bool {
+		return reader.Sources()[2].Status == SourceEOF
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSourceStatusString(t *testing.T) {
+	require.Equal(t, "reading", SourceReading.String())
+	require.Equal(t, "EOF", SourceEOF.String())
+	require.Equal(t, "failed", SourceFailed.String())
+	require.Equal(t, "SourceStatus(7)", SourceStatus(7).String())
+}
+
+type errorReader struct {
+	err error
+}
+
+func (r *errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,141 @@
+package logparser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SyslogParser parses lines in the RFC 5424 syslog format:
+//
+//	<165>1 2024-01-01T10:00:00Z host app 1234 ID47 [meta seq="1"] This is a notice
+//
+// The level is derived from the severity in the priority: emergency to error
+// are Error, warning is Warn, notice and informational are Info and debug is Debug.
+// The message is the free-form part after the structured data. Lines without
+// a timestamp are invalid, since entries are ordered by it.
+//
+// The hostname, app name, process ID and message ID become the "hostname",
+// "app_name", "proc_id" and "msg_id" fields, unless they are "-". Parameters
+// of structured data become fields named by the element ID and the parameter
+// name joined with a dot, like "meta.seq". Their values are quoted, so like
+// quoted values of LogfmtParser they are strings unless they hold durations
+// or times.
+type SyslogParser struct{}
+
+func (SyslogParser) Parse(line string) (LogEntry, error) {
+	if !strings.HasPrefix(line, "<") {
+		return LogEntry{}, invalidLine("syslog", line)
+	}
+	end := strings.IndexByte(line, '>')
+	if end < 2 || end > 4 {
+		return LogEntry{}, invalidLine("syslog", line)
+	}
+	priority, err := strconv.Atoi(line[1:end])
+	if err != nil || priority < 0 || priority > 191 {
+		return LogEntry{}, invalidLine("syslog", line)
+	}
+
+	// Version, timestamp, hostname, app name, process ID and message ID,
+	// followed by structured data and the message.
+	header := strings.SplitN(line[end+1:], " ", 7)
+	if len(header) < 7 || header[0] != "1" {
+		return LogEntry{}, invalidLine("syslog", line)
+	}
+	ts, ok := parseTimestamp(header[1])
+	if !ok {
+		return LogEntry{}, invalidLine("syslog", line)
+	}
+
+	var fields Fields
+	for i, key := range []string{"hostname", "app_name", "proc_id", "msg_id"} {
+		if header[i+2] != "-" {
+			fields = append(fields, Field{Key: key, Value: StringValue(header[i+2])})
+		}
+	}
+	message, ok := parseStructuredData(header[6], &fields)
+	if !ok {
+		return LogEntry{}, invalidLine("syslog", line)
+	}
+
+	return LogEntry{
+		Timestamp: ts,
+		Level:     syslogLevel(priority % 8),
+		Message:   strings.TrimPrefix(message, "\uFEFF"),
+		Fields:    fields,
+	}, nil
+}
+
+func syslogLevel(severity int) LogLevel {
+	switch {
+	case severity <= 3:
+		return LogLevelError
+	case severity == 4:
+		return LogLevelWarn
+	case severity <= 6:
+		return LogLevelInfo
+	default:
+		return LogLevelDebug
+	}
+}
+
+// parseStructuredData appends parameters of the structured data at the start
+// of s to fields and returns the message following it.
+func parseStructuredData(s string, fields *Fields) (string, bool) {
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		return cutSpace(rest)
+	}
+	if !strings.HasPrefix(s, "[") {
+		return "", false
+	}
+	for strings.HasPrefix(s, "[") {
+		var ok bool
+		if s, ok = parseStructuredElement(s[1:], fields); !ok {
+			return "", false
+		}
+	}
+	return cutSpace(s)
+}
+
+// parseStructuredElement parses an element after its opening "[",
+// like `meta seq="1" note="a \"b\""]`, and returns the rest of s.
+func parseStructuredElement(s string, fields *Fields) (string, bool) {
+	end := strings.IndexAny(s, " ]")
+	if end <= 0 {
+		return "", false
+	}
+	id := s[:end]
+	s = s[end:]
+	for strings.HasPrefix(s, " ") {
+		eq := strings.Index(s, `="`)
+		if eq < 2 {
+			return "", false
+		}
+		name := s[1:eq]
+		s = s[eq+2:]
+
+		var value strings.Builder
+		closed := false
+		for i := 0; i < len(s); i++ {
+			if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(`"\\]`, s[i+1]) >= 0 {
+				i++
+			} else if s[i] == '"' {
+				s, closed = s[i+1:], true
+				break
+			}
+			value.WriteByte(s[i])
+		}
+		if !closed {
+			return "", false
+		}
+		*fields = append(*fields, Field{Key: id + "." + name, Value: inferTextValue(value.String())})
+	}
+	return strings.CutPrefix(s, "]")
+}
+
+// cutSpace removes the space separating the message, which is absent if there is no message.
+func cutSpace(s string) (string, bool) {
+	if s == "" {
+		return "", true
+	}
+	return strings.CutPrefix(s, " ")
+}
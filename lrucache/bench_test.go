@@ -0,0 +1,113 @@
+package lrucache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pointerCache is the straightforward LRU cache with heap allocated
+// list nodes, kept as a baseline for benchmarks.
+type pointerCache struct {
+	capacity int
+	items    map[int]*pointerEntry
+	root     pointerEntry
+}
+
+type pointerEntry struct {
+	key, value int
+	prev, next *pointerEntry
+}
+
+func newPointerCache(capacity int) *pointerCache {
+	c := &pointerCache{capacity: capacity, items: make(map[int]*pointerEntry, capacity)}
+	c.root.prev = &c.root
+	c.root.next = &c.root
+	return c
+}
+
+func (c *pointerCache) Get(key int) (int, bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return 0, false
+	}
+	c.unlink(e)
+	c.pushBack(e)
+	return e.value, true
+}
+
+func (c *pointerCache) Set(key, value int) {
+	if e, ok := c.items[key]; ok {
+		e.value = value
+		c.unlink(e)
+		c.pushBack(e)
+		return
+	}
+	if len(c.items) >= c.capacity {
+		oldest := c.root.next
+		c.unlink(oldest)
+		delete(c.items, oldest.key)
+	}
+	e := &pointerEntry{key: key, value: value}
+	c.pushBack(e)
+	c.items[key] = e
+}
+
+func (c *pointerCache) pushBack(e *pointerEntry) {
+	e.prev = c.root.prev
+	e.next = &c.root
+	e.prev.next = e
+	c.root.prev = e
+}
+
+func (c *pointerCache) unlink(e *pointerEntry) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+}
+
+type benchCache interface {
+	Get(key int) (int, bool)
+	Set(key, value int)
+}
+
+const benchCapacity = 1 << 14
+
+func benchmarkSet(b *testing.B, c benchCache) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		// Four times more keys than capacity, so most Sets evict.
+		c.Set(i&(4*benchCapacity-1), i)
+	}
+}
+
+func benchmarkGetSet(b *testing.B, c benchCache) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		key := (i * 7919) & (2*benchCapacity - 1)
+		if _, ok := c.Get(key); !ok {
+			c.Set(key, i)
+		}
+	}
+}
+
+func BenchmarkSetSlab(b *testing.B)    { benchmarkSet(b, New(benchCapacity)) }
+func BenchmarkSetPointer(b *testing.B) { benchmarkSet(b, newPointerCache(benchCapacity)) }
+
+func BenchmarkGetSetSlab(b *testing.B)    { benchmarkGetSet(b, New(benchCapacity)) }
+func BenchmarkGetSetPointer(b *testing.B) { benchmarkGetSet(b, newPointerCache(benchCapacity)) }
+
+func TestCacheZeroAllocs(t *testing.T) {
+	c := New(1000)
+	for i := 0; i < 1000; i++ {
+		c.Set(i, i)
+	}
+
+	key := 0
+	allocs := testing.AllocsPerRun(10000, func() {
+		// Every Set inserts a new key and evicts the oldest one.
+		c.Set(1000+key, key)
+		c.Get(key + 500)
+		key++
+	})
+	require.Zero(t, allocs)
+}
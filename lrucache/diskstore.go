@@ -0,0 +1,262 @@
+package lrucache
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+const (
+	opSet    byte = 1
+	opDelete byte = 2
+
+	// recordSize is the size of a single log record:
+	// 1 byte op, 8 byte key, 8 byte value and 4 byte CRC-32 of the preceding bytes.
+	recordSize = 1 + 8 + 8 + 4
+
+	// minCompactRecords is the number of dead records below which
+	// compaction is not worth the rewrite.
+	minCompactRecords = 1024
+)
+
+var ErrCorruptedLog = errors.New("corrupted disk store log")
+
+// DiskStore is an on-disk key-value store with LRU eviction.
+//
+// Every modification is appended to a log file, and an in-memory index
+// maps keys to their latest record. Records that are no longer referenced
+// by the index are reclaimed by compaction, which rewrites the log with
+// live records only.
+//
+// DiskStore is not safe for concurrent use.
+type DiskStore struct {
+	path    string
+	f       *os.File
+	records int64
+
+	// index maps keys to record numbers in the log and keeps
+	// their recency order.
+	index *LruCache
+}
+
+// OpenDiskStore opens the log at path, creating it if necessary,
+// and rebuilds the index from it.
+//
+// The store holds at most capacity keys and evicts least recently used
+// ones when it is full. Recency order is not persisted: after reopening,
+// keys are ordered by the time they were last written.
+//
+// A partially written record at the end of the log, left by a crash,
+// is discarded. Corrupted records before it make OpenDiskStore fail
+// with ErrCorruptedLog.
+func OpenDiskStore(path string, capacity int) (*DiskStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DiskStore{path: path, f: f}
+	d.index = New(capacity)
+	if err := d.replay(); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// Get returns value associated with the key.
+//
+// The second value is a bool that is true if the key exists in the store,
+// and false if not.
+func (d *DiskStore) Get(key int) (int, bool, error) {
+	n, ok := d.index.Get(key)
+	if !ok {
+		return 0, false, nil
+	}
+
+	op, k, value, err := d.readRecord(int64(n))
+	if err != nil {
+		return 0, false, err
+	}
+	if op != opSet || k != key {
+		return 0, false, fmt.Errorf("%w: record %d does not hold key %d", ErrCorruptedLog, n, key)
+	}
+	return value, true, nil
+}
+
+// Contains reports whether the key exists in the store
+// without updating its access time.
+func (d *DiskStore) Contains(key int) bool {
+	return d.index.Contains(key)
+}
+
+// Set updates value associated with the key.
+//
+// If there is no key in the store new (key, value) pair is created,
+// possibly evicting the least recently used key.
+func (d *DiskStore) Set(key, value int) error {
+	if d.index.Cap() == 0 {
+		return nil
+	}
+
+	if !d.index.Contains(key) && d.index.Len() >= d.index.Cap() {
+		// Without a tombstone the evicted key would come back on the next replay.
+		oldest, _, _ := d.index.RemoveOldest()
+		if _, err := d.appendRecord(opDelete, oldest, 0); err != nil {
+			return err
+		}
+	}
+
+	n, err := d.appendRecord(opSet, key, value)
+	if err != nil {
+		return err
+	}
+	d.index.Set(key, int(n))
+	return d.maybeCompact()
+}
+
+// Delete removes the key from the store.
+//
+// Returns true if the key was present.
+func (d *DiskStore) Delete(key int) (bool, error) {
+	if !d.index.Contains(key) {
+		return false, nil
+	}
+	if _, err := d.appendRecord(opDelete, key, 0); err != nil {
+		return false, err
+	}
+	d.index.Delete(key)
+	return true, d.maybeCompact()
+}
+
+// Len returns the number of keys in the store.
+func (d *DiskStore) Len() int {
+	return d.index.Len()
+}
+
+// Cap returns the maximum number of keys the store can hold.
+func (d *DiskStore) Cap() int {
+	return d.index.Cap()
+}
+
+// Compact rewrites the log so that it contains only live records,
+// preserving their recency order.
+func (d *DiskStore) Compact() error {
+	tmpPath := d.path + ".compact"
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	index := New(d.index.Cap())
+	var (
+		buf     [recordSize]byte
+		records int64
+	)
+	for key, n := range d.index.All() {
+		var value int
+		_, _, value, err = d.readRecord(int64(n))
+		if err != nil {
+			break
+		}
+		encodeRecord(buf[:], opSet, key, value)
+		if _, err = tmp.WriteAt(buf[:], records*recordSize); err != nil {
+			break
+		}
+		index.Set(key, int(records))
+		records++
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, d.path)
+	}
+	if err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	_ = d.f.Close()
+	d.f = tmp
+	d.records = records
+	d.index = index
+	return nil
+}
+
+// Close closes the underlying log file.
+func (d *DiskStore) Close() error {
+	return d.f.Close()
+}
+
+func (d *DiskStore) maybeCompact() error {
+	dead := d.records - int64(d.index.Len())
+	if dead < minCompactRecords || dead < int64(d.index.Len()) {
+		return nil
+	}
+	return d.Compact()
+}
+
+func (d *DiskStore) replay() error {
+	info, err := d.f.Stat()
+	if err != nil {
+		return err
+	}
+	for n := int64(0); ; n++ {
+		op, key, _, err := d.readRecord(n)
+		// Only the last record may be torn, a bad one before it
+		// means the log was damaged after it was written.
+		torn := errors.Is(err, ErrCorruptedLog) && (n+1)*recordSize >= info.Size()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || torn {
+			d.records = n
+			return d.f.Truncate(n * recordSize)
+		}
+		if err != nil {
+			return err
+		}
+
+		switch op {
+		case opSet:
+			d.index.Set(key, int(n))
+		case opDelete:
+			d.index.Delete(key)
+		}
+	}
+}
+
+func (d *DiskStore) appendRecord(op byte, key, value int) (int64, error) {
+	var buf [recordSize]byte
+	encodeRecord(buf[:], op, key, value)
+
+	n := d.records
+	if _, err := d.f.WriteAt(buf[:], n*recordSize); err != nil {
+		return 0, err
+	}
+	d.records++
+	return n, nil
+}
+
+func (d *DiskStore) readRecord(n int64) (op byte, key, value int, err error) {
+	var buf [recordSize]byte
+	if _, err := d.f.ReadAt(buf[:], n*recordSize); err != nil {
+		return 0, 0, 0, err
+	}
+	if crc32.ChecksumIEEE(buf[:recordSize-4]) != binary.BigEndian.Uint32(buf[recordSize-4:]) {
+		return 0, 0, 0, fmt.Errorf("%w: bad checksum of record %d", ErrCorruptedLog, n)
+	}
+	op = buf[0]
+	key = int(int64(binary.BigEndian.Uint64(buf[1:9])))
+	value = int(int64(binary.BigEndian.Uint64(buf[9:17])))
+	return op, key, value, nil
+}
+
+func encodeRecord(buf []byte, op byte, key, value int) {
+	buf[0] = op
+	binary.BigEndian.PutUint64(buf[1:9], uint64(int64(key)))
+	binary.BigEndian.PutUint64(buf[9:17], uint64(int64(value)))
+	binary.BigEndian.PutUint32(buf[17:], crc32.ChecksumIEEE(buf[:17]))
+}
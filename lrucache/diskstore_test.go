@@ -0,0 +1,168 @@
+package lrucache
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskStoreReopen(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "store.log")
+
+	d, err := OpenDiskStore(path, 3)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, d.Set(i, i*10))
+	}
+	require.NoError(t, d.Set(1, 100))
+	ok, err := d.Delete(2)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, d.Close())
+
+	d, err = OpenDiskStore(path, 3)
+	require.NoError(t, err)
+	defer d.Close()
+
+	require.Equal(t, 2, d.Len())
+	v, ok, err := d.Get(1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 100, v)
+
+	_, ok, err = d.Get(2)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestDiskStoreEviction(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "store.log")
+
+	d, err := OpenDiskStore(path, 2)
+	require.NoError(t, err)
+
+	require.NoError(t, d.Set(1, 1))
+	require.NoError(t, d.Set(2, 2))
+	_, _, err = d.Get(1)
+	require.NoError(t, err)
+	require.NoError(t, d.Set(3, 3))
+
+	require.False(t, d.Contains(2))
+	require.NoError(t, d.Close())
+
+	// Evicted key must not come back after replay.
+	d, err = OpenDiskStore(path, 2)
+	require.NoError(t, err)
+	defer d.Close()
+	require.False(t, d.Contains(2))
+	require.Equal(t, 2, d.Len())
+}
+
+func TestDiskStoreCompact(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "store.log")
+
+	d, err := OpenDiskStore(path, 10)
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, d.Set(i%4, i))
+	}
+	_, _, err = d.Get(0)
+	require.NoError(t, err)
+
+	require.NoError(t, d.Compact())
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, int64(4*recordSize), info.Size())
+	require.Equal(t, []int{1, 2, 3, 0}, slices.Collect(d.index.Keys()))
+
+	for i := 0; i < 4; i++ {
+		v, ok, err := d.Get(i)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, 96+i, v)
+	}
+	require.NoError(t, d.Close())
+}
+
+func TestDiskStoreTornWrite(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "store.log")
+
+	d, err := OpenDiskStore(path, 10)
+	require.NoError(t, err)
+	require.NoError(t, d.Set(1, 1))
+	require.NoError(t, d.Set(2, 2))
+	require.NoError(t, d.Close())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-5))
+
+	d, err = OpenDiskStore(path, 10)
+	require.NoError(t, err)
+	defer d.Close()
+
+	require.Equal(t, 1, d.Len())
+	require.True(t, d.Contains(1))
+	require.NoError(t, d.Set(3, 3))
+
+	v, ok, err := d.Get(3)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 3, v)
+}
+
+func TestDiskStoreCorruptedRecord(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		record int64
+		err    error
+		keys   []int
+	}{
+		{name: "last", record: 2, keys: []int{1, 2}},
+		{name: "middle", record: 1, err: ErrCorruptedLog},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), "store.log")
+
+			d, err := OpenDiskStore(path, 10)
+			require.NoError(t, err)
+			for key := 1; key <= 3; key++ {
+				require.NoError(t, d.Set(key, key))
+			}
+			require.NoError(t, d.Close())
+
+			f, err := os.OpenFile(path, os.O_RDWR, 0)
+			require.NoError(t, err)
+			_, err = f.WriteAt([]byte{0xff}, tc.record*recordSize+5)
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+
+			d, err = OpenDiskStore(path, 10)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			defer d.Close()
+
+			require.Equal(t, len(tc.keys), d.Len())
+			for _, key := range tc.keys {
+				require.True(t, d.Contains(key))
+			}
+		})
+	}
+}
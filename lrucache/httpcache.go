@@ -0,0 +1,382 @@
+package lrucache
+
+import (
+	"bytes"
+	"hash/fnv"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStatusHeader is the response header in which HTTPCache reports
+// how the request was served: one of CacheHit, CacheMiss, CacheRevalidated
+// or CacheBypass.
+const CacheStatusHeader = "X-Cache"
+
+const (
+	// CacheHit means the response was served from cache.
+	CacheHit = "HIT"
+	// CacheMiss means the response was produced by the wrapped handler.
+	CacheMiss = "MISS"
+	// CacheRevalidated means a stale cached response was confirmed
+	// by the wrapped handler with 304 Not Modified and served from cache.
+	CacheRevalidated = "REVALIDATED"
+	// CacheBypass means the request is not cacheable and went straight
+	// to the wrapped handler.
+	CacheBypass = "BYPASS"
+)
+
+// HTTPCache is an http.Handler that caches responses of the wrapped handler.
+//
+// Responses to GET and HEAD requests are keyed by method, URL and values of
+// request headers listed in their Vary header. Only 200 OK responses are
+// stored, and only if their Cache-Control allows it: no-store and private
+// responses are never stored, and max-age sets for how long they are fresh.
+// Stale responses with an ETag are revalidated with the wrapped handler
+// using If-None-Match. Requests with If-None-Match matching the cached ETag
+// get 304 Not Modified.
+//
+// Recency of responses is tracked by an LruCache, so the least recently
+// used ones are evicted first.
+//
+// HTTPCache is safe for concurrent use.
+type HTTPCache struct {
+	next http.Handler
+	now  func() time.Time
+
+	mu sync.Mutex
+	// lru holds hashes of cache keys, its values are unused.
+	lru       *LruCache
+	responses map[int]*cachedResponse
+	// vary maps method and URL to header names listed in Vary
+	// of the responses stored for them.
+	vary map[string]*varyHeaders
+}
+
+type cachedResponse struct {
+	key     string
+	primary string
+	vary    []string
+	header  http.Header
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+type varyHeaders struct {
+	names []string
+	// refs is the number of cached responses using these headers.
+	refs int
+}
+
+// NewHTTPCache creates an HTTPCache storing up to capacity responses
+// of next.
+func NewHTTPCache(capacity int, next http.Handler) *HTTPCache {
+	c := &HTTPCache{
+		next:      next,
+		now:       time.Now,
+		responses: make(map[int]*cachedResponse),
+		vary:      make(map[string]*varyHeaders),
+	}
+	c.lru = NewWithEvict(capacity, func(hash, _ int) {
+		c.drop(hash)
+	})
+	return c
+}
+
+// Len returns the number of cached responses.
+func (c *HTTPCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *HTTPCache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		c.bypass(w, r)
+		return
+	}
+	reqDirectives := parseCacheControl(r.Header)
+	if _, ok := reqDirectives["no-store"]; ok {
+		c.bypass(w, r)
+		return
+	}
+	_, noCache := reqDirectives["no-cache"]
+
+	primary := r.Method + " " + r.URL.String()
+	now := c.now()
+
+	c.mu.Lock()
+	key := c.key(primary, r.Header)
+	hash := hashKey(key)
+	cached, ok := c.responses[hash]
+	if ok && cached.key == key {
+		c.lru.Get(hash)
+	} else {
+		cached = nil
+	}
+	c.mu.Unlock()
+
+	if cached != nil && !noCache && now.Before(cached.expires) {
+		serveCached(w, r, cached, CacheHit)
+		return
+	}
+
+	// Conditional headers of the client are answered by the cache itself,
+	// the wrapped handler either gets a full request or a revalidation.
+	upstream := r.Clone(r.Context())
+	upstream.Header.Del("If-None-Match")
+	upstream.Header.Del("If-Modified-Since")
+	if cached != nil && cached.etag != "" {
+		upstream.Header.Set("If-None-Match", cached.etag)
+	}
+
+	rec := &responseRecorder{header: make(http.Header)}
+	c.next.ServeHTTP(rec, upstream)
+
+	if cached != nil && rec.status == http.StatusNotModified {
+		refreshed := *cached
+		refreshed.expires = expiresAt(now, rec.header)
+		c.store(&refreshed)
+		serveCached(w, r, &refreshed, CacheRevalidated)
+		return
+	}
+
+	if resp, ok := c.storable(now, primary, r.Header, rec); ok {
+		c.store(resp)
+		serveCached(w, r, resp, CacheMiss)
+		return
+	}
+
+	rec.header.Set(CacheStatusHeader, CacheMiss)
+	rec.writeTo(w)
+}
+
+func (c *HTTPCache) bypass(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(CacheStatusHeader, CacheBypass)
+	c.next.ServeHTTP(w, r)
+}
+
+// key builds cache key for the request from its method and URL
+// and values of headers listed in Vary of responses cached for them.
+// Must be called with c.mu held.
+func (c *HTTPCache) key(primary string, header http.Header) string {
+	v, ok := c.vary[primary]
+	if !ok {
+		return primary
+	}
+	return varyKey(primary, v.names, header)
+}
+
+func (c *HTTPCache) storable(
+	now time.Time, primary string, reqHeader http.Header, rec *responseRecorder,
+) (*cachedResponse, bool) {
+	if rec.status != http.StatusOK {
+		return nil, false
+	}
+	directives := parseCacheControl(rec.header)
+	if _, ok := directives["no-store"]; ok {
+		return nil, false
+	}
+	if _, ok := directives["private"]; ok {
+		return nil, false
+	}
+
+	etag := rec.header.Get("ETag")
+	expires := expiresAt(now, rec.header)
+	if etag == "" && !now.Before(expires) {
+		return nil, false
+	}
+
+	var names []string
+	for _, v := range rec.header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "*" {
+				return nil, false
+			}
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	return &cachedResponse{
+		key:     varyKey(primary, names, reqHeader),
+		primary: primary,
+		vary:    names,
+		header:  rec.header,
+		body:    rec.body.Bytes(),
+		etag:    etag,
+		expires: expires,
+	}, true
+}
+
+func (c *HTTPCache) store(resp *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Responses for the same URL share one set of Vary headers, a different
+	// set from a newer response replaces all of them.
+	var current []string
+	if v, ok := c.vary[resp.primary]; ok {
+		current = v.names
+	}
+	if !slices.Equal(current, resp.vary) {
+		for hash, old := range c.responses {
+			if old.primary == resp.primary {
+				c.lru.Delete(hash)
+				c.drop(hash)
+			}
+		}
+	}
+
+	hash := hashKey(resp.key)
+	if c.lru.Contains(hash) {
+		c.lru.Delete(hash)
+		c.drop(hash)
+	}
+	if c.lru.Cap() == 0 {
+		return
+	}
+
+	c.lru.Set(hash, 0)
+	c.responses[hash] = resp
+	if resp.vary != nil {
+		v, ok := c.vary[resp.primary]
+		if !ok {
+			v = &varyHeaders{names: resp.vary}
+			c.vary[resp.primary] = v
+		}
+		v.refs++
+	}
+}
+
+// drop forgets the response stored under hash.
+// Must be called with c.mu held.
+func (c *HTTPCache) drop(hash int) {
+	resp, ok := c.responses[hash]
+	if !ok {
+		return
+	}
+	delete(c.responses, hash)
+
+	if v, ok := c.vary[resp.primary]; ok {
+		v.refs--
+		if v.refs == 0 {
+			delete(c.vary, resp.primary)
+		}
+	}
+}
+
+func varyKey(primary string, names []string, header http.Header) string {
+	var b strings.Builder
+	b.WriteString(primary)
+	for _, name := range names {
+		b.WriteString("\n")
+		b.WriteString(name)
+		b.WriteString(":")
+		b.WriteString(strings.Join(header.Values(name), ","))
+	}
+	return b.String()
+}
+
+func hashKey(key string) int {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum64())
+}
+
+func serveCached(w http.ResponseWriter, r *http.Request, resp *cachedResponse, status string) {
+	header := w.Header()
+	for name, values := range resp.header {
+		header[name] = slices.Clone(values)
+	}
+	header.Set(CacheStatusHeader, status)
+
+	if resp.etag != "" && etagMatches(r.Header.Get("If-None-Match"), resp.etag) {
+		header.Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(resp.body)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(resp.body)
+	}
+}
+
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+func expiresAt(now time.Time, header http.Header) time.Time {
+	maxAge, ok := parseCacheControl(header)["max-age"]
+	if !ok {
+		return now
+	}
+	seconds, err := strconv.Atoi(maxAge)
+	if err != nil || seconds <= 0 {
+		return now
+	}
+	return now.Add(time.Duration(seconds) * time.Second)
+}
+
+func parseCacheControl(header http.Header) map[string]string {
+	directives := make(map[string]string)
+	for _, v := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(v, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name != "" {
+				directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+			}
+		}
+	}
+	return directives
+}
+
+// responseRecorder buffers response of the wrapped handler.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(p)
+}
+
+func (r *responseRecorder) writeTo(w http.ResponseWriter) {
+	header := w.Header()
+	for name, values := range r.header {
+		header[name] = values
+	}
+	w.WriteHeader(max(r.status, http.StatusOK))
+	_, _ = w.Write(r.body.Bytes())
+}
@@ -0,0 +1,203 @@
+package lrucache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type countingHandler struct {
+	calls   int
+	handler http.HandlerFunc
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls++
+	h.handler(w, r)
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestHTTPCache(capacity int, h http.Handler) (*HTTPCache, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}
+	c := NewHTTPCache(capacity, h)
+	c.now = clock.Now
+	return c, clock
+}
+
+func doRequest(h http.Handler, method, url string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHTTPCacheMaxAge(t *testing.T) {
+	t.Parallel()
+	h := &countingHandler{handler: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = fmt.Fprint(w, "pong!")
+	}}
+	c, clock := newTestHTTPCache(10, h)
+
+	rec := doRequest(c, http.MethodGet, "/ping", nil)
+	require.Equal(t, "pong!", rec.Body.String())
+	require.Equal(t, CacheMiss, rec.Header().Get(CacheStatusHeader))
+
+	clock.now = clock.now.Add(59 * time.Second)
+	rec = doRequest(c, http.MethodGet, "/ping", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "pong!", rec.Body.String())
+	require.Equal(t, CacheHit, rec.Header().Get(CacheStatusHeader))
+	require.Equal(t, 1, h.calls)
+
+	clock.now = clock.now.Add(time.Second)
+	rec = doRequest(c, http.MethodGet, "/ping", nil)
+	require.Equal(t, CacheMiss, rec.Header().Get(CacheStatusHeader))
+	require.Equal(t, 2, h.calls)
+
+	rec = doRequest(c, http.MethodGet, "/ping?x=1", nil)
+	require.Equal(t, CacheMiss, rec.Header().Get(CacheStatusHeader))
+	require.Equal(t, 3, h.calls)
+}
+
+func TestHTTPCacheNoStore(t *testing.T) {
+	t.Parallel()
+	h := &countingHandler{handler: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", r.URL.Query().Get("cc"))
+		_, _ = fmt.Fprint(w, "body")
+	}}
+	c, _ := newTestHTTPCache(10, h)
+
+	for i := 0; i < 2; i++ {
+		doRequest(c, http.MethodGet, "/?cc=no-store,max-age=60", nil)
+		doRequest(c, http.MethodGet, "/?cc=private,max-age=60", nil)
+		doRequest(c, http.MethodGet, "/?cc=", nil)
+	}
+	require.Equal(t, 6, h.calls)
+	require.Zero(t, c.Len())
+
+	// Requests with no-store and unsafe methods go straight to the handler.
+	doRequest(c, http.MethodGet, "/?cc=max-age=60", nil)
+	rec := doRequest(c, http.MethodGet, "/?cc=max-age=60", http.Header{"Cache-Control": {"no-store"}})
+	require.Equal(t, CacheBypass, rec.Header().Get(CacheStatusHeader))
+	rec = doRequest(c, http.MethodPost, "/?cc=max-age=60", nil)
+	require.Equal(t, CacheBypass, rec.Header().Get(CacheStatusHeader))
+	require.Equal(t, 9, h.calls)
+}
+
+func TestHTTPCacheETag(t *testing.T) {
+	t.Parallel()
+	version := 1
+	h := &countingHandler{handler: func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"v%d"`, version)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "max-age=10")
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = fmt.Fprintf(w, "version %d", version)
+	}}
+	c, clock := newTestHTTPCache(10, h)
+
+	rec := doRequest(c, http.MethodGet, "/doc", nil)
+	require.Equal(t, "version 1", rec.Body.String())
+
+	// Conditional requests are answered from cache.
+	rec = doRequest(c, http.MethodGet, "/doc", http.Header{"If-None-Match": {`"v1"`}})
+	require.Equal(t, http.StatusNotModified, rec.Code)
+	require.Empty(t, rec.Body.String())
+	require.Equal(t, CacheHit, rec.Header().Get(CacheStatusHeader))
+	require.Equal(t, 1, h.calls)
+
+	// Stale response is revalidated with the handler.
+	clock.now = clock.now.Add(time.Minute)
+	rec = doRequest(c, http.MethodGet, "/doc", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "version 1", rec.Body.String())
+	require.Equal(t, CacheRevalidated, rec.Header().Get(CacheStatusHeader))
+	require.Equal(t, 2, h.calls)
+
+	rec = doRequest(c, http.MethodGet, "/doc", nil)
+	require.Equal(t, CacheHit, rec.Header().Get(CacheStatusHeader))
+	require.Equal(t, 2, h.calls)
+
+	// Changed resource replaces the cached one.
+	version = 2
+	clock.now = clock.now.Add(time.Minute)
+	rec = doRequest(c, http.MethodGet, "/doc", http.Header{"If-None-Match": {`"v1"`}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "version 2", rec.Body.String())
+	require.Equal(t, CacheMiss, rec.Header().Get(CacheStatusHeader))
+	require.Equal(t, 1, c.Len())
+}
+
+func TestHTTPCacheVary(t *testing.T) {
+	t.Parallel()
+	h := &countingHandler{handler: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		_, _ = fmt.Fprint(w, r.Header.Get("Accept-Language"))
+	}}
+	c, _ := newTestHTTPCache(10, h)
+
+	for i := 0; i < 2; i++ {
+		rec := doRequest(c, http.MethodGet, "/", http.Header{"Accept-Language": {"en"}})
+		require.Equal(t, "en", rec.Body.String())
+		rec = doRequest(c, http.MethodGet, "/", http.Header{"Accept-Language": {"ru"}})
+		require.Equal(t, "ru", rec.Body.String())
+	}
+	require.Equal(t, 2, h.calls)
+	require.Equal(t, 2, c.Len())
+}
+
+func TestHTTPCacheEviction(t *testing.T) {
+	t.Parallel()
+	h := &countingHandler{handler: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = fmt.Fprint(w, r.URL.Path)
+	}}
+	c, _ := newTestHTTPCache(2, h)
+
+	doRequest(c, http.MethodGet, "/a", nil)
+	doRequest(c, http.MethodGet, "/b", nil)
+	doRequest(c, http.MethodGet, "/a", nil)
+	doRequest(c, http.MethodGet, "/c", nil)
+	require.Equal(t, 3, h.calls)
+	require.Equal(t, 2, c.Len())
+
+	rec := doRequest(c, http.MethodGet, "/a", nil)
+	require.Equal(t, CacheHit, rec.Header().Get(CacheStatusHeader))
+	rec = doRequest(c, http.MethodGet, "/b", nil)
+	require.Equal(t, CacheMiss, rec.Header().Get(CacheStatusHeader))
+	require.Len(t, c.responses, 2)
+}
+
+func TestHTTPCacheHead(t *testing.T) {
+	t.Parallel()
+	h := &countingHandler{handler: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = fmt.Fprint(w, "pong!")
+	}}
+	c, _ := newTestHTTPCache(10, h)
+
+	doRequest(c, http.MethodHead, "/ping", nil)
+	rec := doRequest(c, http.MethodHead, "/ping", nil)
+	require.Equal(t, CacheHit, rec.Header().Get(CacheStatusHeader))
+	require.Empty(t, rec.Body.String())
+	require.Equal(t, "5", rec.Header().Get("Content-Length"))
+}
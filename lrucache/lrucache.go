@@ -1,12 +1,53 @@
 package lrucache
 
+import "iter"
+
+// root is the index of the sentinel node of the recency list in the slab.
+const root = 0
+
+type entry struct {
+	key, value int
+	prev, next int
+}
+
+// LruCache stores its entries in a slab preallocated by New and links them
+// by index instead of pointers, so Get and Set don't allocate once
+// the cache is created.
 type LruCache struct {
-	// Add your fields here
+	capacity int
+	items    map[int]int
+	onEvict  func(key, value int)
+
+	// slab holds capacity entries after the sentinel at index root.
+	// slab[root].next is the least recently used entry and slab[root].prev
+	// is the most recently used one.
+	slab []entry
+	// free is the index of the first unused entry in the slab, unused
+	// entries are chained through their next field. Zero means the slab is full.
+	free int
 }
 
 // Creates a new LruCache with the given capacity.
 func New(capacity int) *LruCache {
-	return nil
+	capacity = max(capacity, 0)
+	l := &LruCache{
+		capacity: capacity,
+		items:    make(map[int]int, capacity),
+		slab:     make([]entry, capacity+1),
+	}
+	l.reset()
+	return l
+}
+
+// Creates a new LruCache with the given capacity that calls onEvict
+// for every element pushed out of the cache by Set or Resize.
+//
+// Elements removed explicitly with Delete, RemoveOldest or Clear are not
+// reported.
+func NewWithEvict(capacity int, onEvict func(key, value int)) *LruCache {
+	l := New(capacity)
+	l.onEvict = onEvict
+	return l
 }
 
 // Get returns value associated with the key.
@@ -14,22 +55,211 @@ func New(capacity int) *LruCache {
 // The second value is a bool that is true if the key exists in the cache,
 // and false if not.
 func (l *LruCache) Get(key int) (int, bool) {
-	return 0, false
+	i, ok := l.items[key]
+	if !ok {
+		return 0, false
+	}
+	l.moveToBack(i)
+	return l.slab[i].value, true
 }
 
 // Set updates value associated with the key.
 //
 // If there is no key in the cache new (key, value) pair is created.
 func (l *LruCache) Set(key, value int) {
+	if l.capacity == 0 {
+		return
+	}
+	if i, ok := l.items[key]; ok {
+		l.slab[i].value = value
+		l.moveToBack(i)
+		return
+	}
+	if len(l.items) >= l.capacity {
+		l.evict()
+	}
+	l.items[key] = l.acquire(key, value)
+}
+
+// Peek returns value associated with the key without
+// updating its access time.
+func (l *LruCache) Peek(key int) (int, bool) {
+	i, ok := l.items[key]
+	if !ok {
+		return 0, false
+	}
+	return l.slab[i].value, true
+}
+
+// Contains reports whether the key exists in the cache
+// without updating its access time.
+func (l *LruCache) Contains(key int) bool {
+	_, ok := l.items[key]
+	return ok
+}
+
+// Delete removes the key from the cache.
+//
+// Returns true if the key was present.
+func (l *LruCache) Delete(key int) bool {
+	i, ok := l.items[key]
+	if !ok {
+		return false
+	}
+	l.release(i)
+	return true
+}
+
+// RemoveOldest removes the least recently used element from the cache
+// and returns it.
+//
+// The last value is false if the cache is empty.
+func (l *LruCache) RemoveOldest() (key, value int, ok bool) {
+	i := l.slab[root].next
+	if i == root {
+		return 0, 0, false
+	}
+	key, value = l.slab[i].key, l.slab[i].value
+	l.release(i)
+	return key, value, true
+}
+
+// Len returns the number of elements in the cache.
+func (l *LruCache) Len() int {
+	return len(l.items)
+}
+
+// Cap returns the maximum number of elements the cache can hold.
+func (l *LruCache) Cap() int {
+	return l.capacity
+}
+
+// Resize changes capacity of the cache, evicting least recently used
+// elements if it no longer fits.
+//
+// Returns the number of evicted elements.
+func (l *LruCache) Resize(newCapacity int) int {
+	newCapacity = max(newCapacity, 0)
+	evicted := 0
+	for len(l.items) > newCapacity {
+		l.evict()
+		evicted++
+	}
+
+	// Move remaining entries to a new slab, keeping their order.
+	old := l.slab
+	l.capacity = newCapacity
+	l.slab = make([]entry, newCapacity+1)
+	l.reset()
+	for i := old[root].next; i != root; i = old[i].next {
+		l.items[old[i].key] = l.acquire(old[i].key, old[i].value)
+	}
+	return evicted
 }
 
 // Range calls function f on all elements of the cache
 // in increasing access time order.
 //
-// Stops earlier if f returns false.
+// Stops earlier if f returns false. It is safe for f to delete
+// the element it was called with.
 func (l *LruCache) Range(f func(key, value int) bool) {
+	for i := l.slab[root].next; i != root; {
+		next := l.slab[i].next
+		if !f(l.slab[i].key, l.slab[i].value) {
+			return
+		}
+		i = next
+	}
+}
+
+// All returns an iterator over (key, value) pairs of the cache
+// in increasing access time order.
+func (l *LruCache) All() iter.Seq2[int, int] {
+	return l.Range
+}
+
+// Keys returns an iterator over keys of the cache
+// in increasing access time order.
+func (l *LruCache) Keys() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		l.Range(func(key, _ int) bool {
+			return yield(key)
+		})
+	}
+}
+
+// Values returns an iterator over values of the cache
+// in increasing access time order.
+func (l *LruCache) Values() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		l.Range(func(_, value int) bool {
+			return yield(value)
+		})
+	}
 }
 
 // Clear removes all elements from the cache.
 func (l *LruCache) Clear() {
+	clear(l.items)
+	l.reset()
+}
+
+// reset empties the recency list and puts every slab entry on the free list.
+func (l *LruCache) reset() {
+	l.slab[root] = entry{prev: root, next: root}
+	for i := 1; i < len(l.slab); i++ {
+		l.slab[i] = entry{next: i + 1}
+	}
+	l.slab[len(l.slab)-1].next = root
+	l.free = root
+	if len(l.slab) > 1 {
+		l.free = 1
+	}
+}
+
+func (l *LruCache) evict() {
+	key, value, ok := l.RemoveOldest()
+	if ok && l.onEvict != nil {
+		l.onEvict(key, value)
+	}
+}
+
+// acquire takes an entry from the free list and makes it the most
+// recently used one. The slab must not be full.
+func (l *LruCache) acquire(key, value int) int {
+	i := l.free
+	l.free = l.slab[i].next
+	l.slab[i] = entry{key: key, value: value}
+	l.pushBack(i)
+	return i
+}
+
+// release unlinks entry i and returns it to the free list.
+func (l *LruCache) release(i int) {
+	delete(l.items, l.slab[i].key)
+	l.unlink(i)
+	l.slab[i] = entry{next: l.free}
+	l.free = i
+}
+
+func (l *LruCache) pushBack(i int) {
+	last := l.slab[root].prev
+	l.slab[i].prev = last
+	l.slab[i].next = root
+	l.slab[last].next = i
+	l.slab[root].prev = i
+}
+
+func (l *LruCache) unlink(i int) {
+	prev, next := l.slab[i].prev, l.slab[i].next
+	l.slab[prev].next = next
+	l.slab[next].prev = prev
+}
+
+func (l *LruCache) moveToBack(i int) {
+	if l.slab[root].prev == i {
+		return
+	}
+	l.unlink(i)
+	l.pushBack(i)
 }
@@ -1,6 +1,8 @@
 package lrucache
 
 import (
+	"maps"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -99,3 +101,103 @@ func TestCacheRangeWithStop(t *testing.T) {
 	require.Equal(t, []int{0, 1, 2}, keys)
 	require.Equal(t, []int{0, 1, 2}, values)
 }
+
+func TestCachePeek(t *testing.T) {
+	t.Parallel()
+	c := New(2)
+
+	c.Set(1, 10)
+	c.Set(2, 20)
+
+	v, ok := c.Peek(1)
+	require.True(t, ok)
+	require.Equal(t, 10, v)
+	require.True(t, c.Contains(1))
+
+	// Neither Peek nor Contains refresh key 1, so it is evicted first.
+	c.Set(3, 30)
+	require.False(t, c.Contains(1))
+
+	_, ok = c.Peek(1)
+	require.False(t, ok)
+}
+
+func TestCacheDelete(t *testing.T) {
+	t.Parallel()
+	c := New(3)
+
+	for i := 0; i < 3; i++ {
+		c.Set(i, i)
+	}
+
+	require.True(t, c.Delete(1))
+	require.False(t, c.Delete(1))
+	require.Equal(t, 2, c.Len())
+	require.Equal(t, []int{0, 2}, slices.Collect(c.Keys()))
+
+	c.Set(3, 3)
+	require.Equal(t, []int{0, 2, 3}, slices.Collect(c.Keys()))
+}
+
+func TestCacheRemoveOldest(t *testing.T) {
+	t.Parallel()
+	c := New(3)
+
+	_, _, ok := c.RemoveOldest()
+	require.False(t, ok)
+
+	c.Set(1, 10)
+	c.Set(2, 20)
+	c.Get(1)
+
+	k, v, ok := c.RemoveOldest()
+	require.True(t, ok)
+	require.Equal(t, 2, k)
+	require.Equal(t, 20, v)
+	require.Equal(t, 1, c.Len())
+}
+
+func TestCacheResize(t *testing.T) {
+	t.Parallel()
+	c := New(5)
+
+	for i := 0; i < 5; i++ {
+		c.Set(i, i)
+	}
+	c.Get(0)
+
+	require.Equal(t, 3, c.Resize(2))
+	require.Equal(t, 2, c.Cap())
+	require.Equal(t, []int{4, 0}, slices.Collect(c.Keys()))
+
+	require.Equal(t, 0, c.Resize(3))
+	c.Set(5, 5)
+	c.Set(6, 6)
+	require.Equal(t, []int{0, 5, 6}, slices.Collect(c.Keys()))
+
+	require.Equal(t, 3, c.Resize(0))
+	c.Set(7, 7)
+	require.Equal(t, 0, c.Len())
+}
+
+func TestCacheIterators(t *testing.T) {
+	t.Parallel()
+	c := New(3)
+
+	for i := 0; i < 3; i++ {
+		c.Set(i, i*10)
+	}
+	c.Get(0)
+
+	require.Equal(t, []int{1, 2, 0}, slices.Collect(c.Keys()))
+	require.Equal(t, []int{10, 20, 0}, slices.Collect(c.Values()))
+	require.Equal(t, map[int]int{0: 0, 1: 10, 2: 20}, maps.Collect(c.All()))
+
+	for k := range c.Keys() {
+		if k == 2 {
+			break
+		}
+		c.Delete(k)
+	}
+	require.Equal(t, []int{2, 0}, slices.Collect(c.Keys()))
+}
@@ -0,0 +1,226 @@
+package lrucache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// NodeBasePath is the URL path prefix under which Node serves its peers.
+	NodeBasePath = "/_lrucache/"
+
+	ringReplicas = 50
+
+	// fetchTimeout bounds requests to peers, so that a hung peer
+	// makes the node load the value locally instead.
+	fetchTimeout = 10 * time.Second
+)
+
+var ErrPeer = errors.New("peer failed to load value")
+
+// Loader loads value for the key on cache miss.
+type Loader func(ctx context.Context, key int) (int, error)
+
+// Node is a member of a peer-to-peer cache.
+//
+// Every key is owned by exactly one node picked with a consistent hash ring.
+// The owner loads missing values with its Loader, while other nodes fetch
+// them from the owner over HTTP. All nodes keep fetched values in their
+// local LruCache.
+//
+// Node is safe for concurrent use.
+type Node struct {
+	loader Loader
+	client *http.Client
+
+	mu    sync.Mutex
+	cache *LruCache
+	self  string
+	ring  *Ring
+	calls map[callKey]*call
+}
+
+// callKey identifies an in-flight load. Loads forwarded to the owner
+// are kept apart from local ones, so that a request of a peer never
+// waits for a request to a peer, which could wait for it in turn.
+type callKey struct {
+	key     int
+	forward bool
+}
+
+// call is an in-flight load of a single key shared by concurrent Gets.
+//
+// The load runs in its own goroutine and is canceled only once all
+// Gets waiting for it give up.
+type call struct {
+	done    chan struct{}
+	value   int
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// NewNode creates a Node caching up to capacity values locally.
+//
+// Until SetPeers is called the node owns all keys.
+func NewNode(capacity int, loader Loader) *Node {
+	return &Node{
+		loader: loader,
+		client: &http.Client{Timeout: fetchTimeout},
+		cache:  New(capacity),
+		ring:   NewRing(ringReplicas),
+		calls:  make(map[callKey]*call),
+	}
+}
+
+// SetPeers sets base URLs of all nodes of the cache, including self,
+// which is the URL this node is reachable at.
+func (n *Node) SetPeers(self string, peers ...string) {
+	ring := NewRing(ringReplicas, self)
+	ring.Add(peers...)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.self = self
+	n.ring = ring
+}
+
+// Get returns value associated with the key, loading it from the owner node
+// or with the Loader on cache miss.
+//
+// If the owner is unreachable, the value is loaded locally.
+// If the owner fails to load the value, the returned error wraps ErrPeer.
+//
+// Concurrent Gets of a key share a single load, which goes on while
+// any of them waits for it, even if the context of the first one is done.
+func (n *Node) Get(ctx context.Context, key int) (int, error) {
+	return n.get(ctx, key, true)
+}
+
+func (n *Node) get(ctx context.Context, key int, forward bool) (int, error) {
+	n.mu.Lock()
+	if v, ok := n.cache.Get(key); ok {
+		n.mu.Unlock()
+		return v, nil
+	}
+
+	owner, self := n.ring.Owner(key), n.self
+	if owner == "" || owner == self {
+		forward = false
+	}
+	ck := callKey{key: key, forward: forward}
+	c, ok := n.calls[ck]
+	if !ok {
+		loadCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &call{done: make(chan struct{}), cancel: cancel}
+		n.calls[ck] = c
+		if !forward {
+			owner = self
+		}
+		go n.do(loadCtx, ck, c, owner, self)
+	}
+	c.waiters++
+	n.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.value, c.err
+	case <-ctx.Done():
+		n.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			// Nobody waits for the load anymore, later Gets start another one.
+			c.cancel()
+			if n.calls[ck] == c {
+				delete(n.calls, ck)
+			}
+		}
+		n.mu.Unlock()
+		return 0, ctx.Err()
+	}
+}
+
+// do runs the load of the call and caches its result.
+func (n *Node) do(ctx context.Context, ck callKey, c *call, owner, self string) {
+	defer c.cancel()
+	c.value, c.err = n.load(ctx, ck.key, owner, self)
+
+	n.mu.Lock()
+	if c.err == nil {
+		n.cache.Set(ck.key, c.value)
+	}
+	if n.calls[ck] == c {
+		delete(n.calls, ck)
+	}
+	n.mu.Unlock()
+	close(c.done)
+}
+
+func (n *Node) load(ctx context.Context, key int, owner, self string) (int, error) {
+	if owner != "" && owner != self {
+		v, err := n.fetch(ctx, owner, key)
+		if err == nil || errors.Is(err, ErrPeer) {
+			return v, err
+		}
+	}
+	return n.loader(ctx, key)
+}
+
+func (n *Node) fetch(ctx context.Context, peer string, key int) (int, error) {
+	url := strings.TrimSuffix(peer, "/") + NodeBasePath + strconv.Itoa(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%w: %s: %s: %s", ErrPeer, peer, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return strconv.Atoi(string(body))
+}
+
+// ServeHTTP answers requests of other nodes for keys owned by this node.
+//
+// Requested keys are always loaded locally, so nodes with different
+// views of the ring never forward a request in a loop.
+func (n *Node) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rest, ok := strings.CutPrefix(r.URL.Path, NodeBasePath)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	key, err := strconv.Atoi(rest)
+	if err != nil {
+		http.Error(w, "bad key", http.StatusBadRequest)
+		return
+	}
+
+	v, err := n.get(r.Context(), key, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	_, _ = fmt.Fprint(w, v)
+}
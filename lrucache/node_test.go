@@ -0,0 +1,247 @@
+package lrucache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCluster struct {
+	nodes   []*Node
+	servers []*httptest.Server
+	loads   []atomic.Int64
+}
+
+func newTestCluster(t *testing.T, size int, loader Loader) *testCluster {
+	c := &testCluster{loads: make([]atomic.Int64, size)}
+
+	urls := make([]string, size)
+	for i := 0; i < size; i++ {
+		node := NewNode(100, func(ctx context.Context, key int) (int, error) {
+			c.loads[i].Add(1)
+			return loader(ctx, key)
+		})
+		srv := httptest.NewServer(node)
+		t.Cleanup(srv.Close)
+
+		c.nodes = append(c.nodes, node)
+		c.servers = append(c.servers, srv)
+		urls[i] = srv.URL
+	}
+	for i, node := range c.nodes {
+		node.SetPeers(urls[i], urls...)
+	}
+	return c
+}
+
+func (c *testCluster) totalLoads() int64 {
+	var total int64
+	for i := range c.loads {
+		total += c.loads[i].Load()
+	}
+	return total
+}
+
+func TestRingOwner(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, NewRing(10).Owner(1))
+
+	r := NewRing(50, "a", "b", "c")
+	owners := map[string]int{}
+	for key := 0; key < 3000; key++ {
+		owners[r.Owner(key)]++
+	}
+	require.Len(t, owners, 3)
+	for _, n := range owners {
+		require.Greater(t, n, 500)
+	}
+
+	// Adding a node only moves keys to the new node.
+	moved := NewRing(50, "a", "b", "c", "d")
+	for key := 0; key < 3000; key++ {
+		if owner := moved.Owner(key); owner != r.Owner(key) {
+			require.Equal(t, "d", owner)
+		}
+	}
+}
+
+func TestNodeLoadsOnOwnerOnly(t *testing.T) {
+	t.Parallel()
+	c := newTestCluster(t, 3, func(_ context.Context, key int) (int, error) {
+		return key * 2, nil
+	})
+
+	ctx := context.Background()
+	for key := 0; key < 30; key++ {
+		for _, node := range c.nodes {
+			v, err := node.Get(ctx, key)
+			require.NoError(t, err)
+			require.Equal(t, key*2, v)
+		}
+	}
+
+	// Every key is loaded exactly once, by its owner.
+	require.Equal(t, int64(30), c.totalLoads())
+	for i := range c.nodes {
+		require.Positive(t, c.loads[i].Load())
+	}
+}
+
+func TestNodeOwnerError(t *testing.T) {
+	t.Parallel()
+	errBackend := errors.New("backend is down")
+	c := newTestCluster(t, 2, func(context.Context, int) (int, error) {
+		return 0, errBackend
+	})
+
+	ctx := context.Background()
+	for key := 0; key < 10; key++ {
+		for _, node := range c.nodes {
+			_, err := node.Get(ctx, key)
+			require.Error(t, err)
+		}
+	}
+	// Failed values are not cached, but non-owners don't retry locally.
+	require.Equal(t, int64(20), c.totalLoads())
+}
+
+func TestNodeUnreachableOwner(t *testing.T) {
+	t.Parallel()
+	c := newTestCluster(t, 2, func(_ context.Context, key int) (int, error) {
+		return key + 1, nil
+	})
+	c.servers[1].Close()
+
+	ctx := context.Background()
+	for key := 0; key < 10; key++ {
+		v, err := c.nodes[0].Get(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, key+1, v)
+	}
+	require.Equal(t, int64(10), c.loads[0].Load())
+}
+
+func TestNodeConcurrentGets(t *testing.T) {
+	t.Parallel()
+	release := make(chan struct{})
+	c := newTestCluster(t, 1, func(_ context.Context, key int) (int, error) {
+		<-release
+		return key, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.nodes[0].Get(context.Background(), 7)
+			require.NoError(t, err)
+			require.Equal(t, 7, v)
+		}()
+	}
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int64(1), c.totalLoads())
+}
+
+func TestNodeSharedLoadOutlivesFirstCaller(t *testing.T) {
+	t.Parallel()
+	started, release := make(chan struct{}), make(chan struct{})
+	c := newTestCluster(t, 1, func(ctx context.Context, key int) (int, error) {
+		close(started)
+		select {
+		case <-release:
+			return key, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	})
+	node := c.nodes[0]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := node.Get(ctx, 7)
+		first <- err
+	}()
+	<-started
+
+	second := make(chan int, 1)
+	go func() {
+		v, err := node.Get(context.Background(), 7)
+		require.NoError(t, err)
+		second <- v
+	}()
+	require.Eventually(t, func() bool {
+		node.mu.Lock()
+		defer node.mu.Unlock()
+		return node.calls[callKey{key: 7}].waiters == 2
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	require.ErrorIs(t, <-first, context.Canceled)
+	close(release)
+	require.Equal(t, 7, <-second)
+	require.Equal(t, int64(1), c.totalLoads())
+}
+
+// barrierTransport holds requests until n of them are sent.
+type barrierTransport struct {
+	wg sync.WaitGroup
+}
+
+func (b *barrierTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b.wg.Done()
+	b.wg.Wait()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNodeInconsistentRings(t *testing.T) {
+	t.Parallel()
+	c := newTestCluster(t, 2, func(_ context.Context, key int) (int, error) {
+		return key, nil
+	})
+	a, b := c.servers[0].URL, c.servers[1].URL
+
+	// Each node thinks the other one owns the key, and both forward their
+	// Gets before serving the request of the other one.
+	key := -1
+	for k := 0; key < 0; k++ {
+		if NewRing(ringReplicas, "a", b).Owner(k) == b && NewRing(ringReplicas, "b", a).Owner(k) == a {
+			key = k
+		}
+	}
+	transport := &barrierTransport{}
+	transport.wg.Add(2)
+	for _, node := range c.nodes {
+		node.client = &http.Client{Transport: transport}
+	}
+	c.nodes[0].SetPeers("a", b)
+	c.nodes[1].SetPeers("b", a)
+
+	results := make(chan int, 2)
+	for _, node := range c.nodes {
+		go func() {
+			v, err := node.Get(context.Background(), key)
+			require.NoError(t, err)
+			results <- v
+		}()
+	}
+	for range c.nodes {
+		select {
+		case v := <-results:
+			require.Equal(t, key, v)
+		case <-time.After(5 * time.Second):
+			t.Fatal("nodes wait for each other")
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package lrucache
+
+import (
+	"hash/crc32"
+	"slices"
+	"strconv"
+)
+
+// Ring is a consistent hash ring that assigns keys to nodes.
+//
+// Each node is placed on the ring several times (replicas) to spread keys
+// evenly, so adding or removing a node only moves keys adjacent to it.
+type Ring struct {
+	replicas int
+	hashes   []uint32
+	owners   map[uint32]string
+}
+
+// NewRing creates a Ring placing every node at the given number of points.
+func NewRing(replicas int, nodes ...string) *Ring {
+	r := &Ring{
+		replicas: max(replicas, 1),
+		owners:   make(map[uint32]string),
+	}
+	r.Add(nodes...)
+	return r
+}
+
+// Add places nodes on the ring.
+func (r *Ring) Add(nodes ...string) {
+	for _, node := range nodes {
+		for i := 0; i < r.replicas; i++ {
+			h := crc32.ChecksumIEEE([]byte(strconv.Itoa(i) + node))
+			if _, ok := r.owners[h]; ok {
+				continue
+			}
+			r.owners[h] = node
+			r.hashes = append(r.hashes, h)
+		}
+	}
+	slices.Sort(r.hashes)
+}
+
+// Owner returns the node responsible for the key.
+//
+// Returns empty string if the ring has no nodes.
+func (r *Ring) Owner(key int) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+
+	h := crc32.ChecksumIEEE([]byte(strconv.Itoa(key)))
+	i, _ := slices.BinarySearch(r.hashes, h)
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.owners[r.hashes[i]]
+}
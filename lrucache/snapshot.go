@@ -0,0 +1,154 @@
+package lrucache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+var (
+	ErrInvalidSnapshot    = errors.New("invalid snapshot")
+	ErrUnsupportedVersion = errors.New("unsupported snapshot version")
+	ErrChecksumMismatch   = errors.New("snapshot checksum mismatch")
+)
+
+var snapshotMagic = [4]byte{'L', 'R', 'U', 'C'}
+
+const snapshotVersion byte = 1
+
+// Snapshot writes all entries of the cache to w in increasing access time
+// order, the same order Range walks them.
+//
+// The format is: 4 byte magic "LRUC", 1 byte version, uvarint entry count,
+// varint encoded key and value of every entry and a big-endian CRC-32 (IEEE)
+// of everything written before it.
+func (l *LruCache) Snapshot(w io.Writer) error {
+	h := crc32.NewIEEE()
+	bw := bufio.NewWriter(io.MultiWriter(w, h))
+
+	buf := make([]byte, 0, 2*binary.MaxVarintLen64)
+	buf = append(buf, snapshotMagic[:]...)
+	buf = append(buf, snapshotVersion)
+	buf = binary.AppendUvarint(buf, uint64(l.Len()))
+	if _, err := bw.Write(buf); err != nil {
+		return err
+	}
+
+	var err error
+	l.Range(func(key, value int) bool {
+		buf = binary.AppendVarint(buf[:0], int64(key))
+		buf = binary.AppendVarint(buf, int64(value))
+		_, err = bw.Write(buf)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	_, err = w.Write(binary.BigEndian.AppendUint32(nil, h.Sum32()))
+	return err
+}
+
+// Restore replaces contents of the cache with entries read from r,
+// which must contain data previously written by Snapshot.
+//
+// Entries keep their recency order, so the restored cache evicts keys
+// in the same order as the one that was saved. If the snapshot holds more
+// entries than the cache capacity, the least recently used ones are dropped.
+//
+// The cache is left untouched if the snapshot is malformed.
+func (l *LruCache) Restore(r io.Reader) error {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	hr := &hashingReader{r: br, h: crc32.NewIEEE()}
+
+	var header [5]byte
+	for i := range header {
+		b, err := hr.ReadByte()
+		if err != nil {
+			return snapshotError(err)
+		}
+		header[i] = b
+	}
+	if [4]byte(header[:4]) != snapshotMagic {
+		return fmt.Errorf("%w: bad magic", ErrInvalidSnapshot)
+	}
+	if header[4] != snapshotVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, header[4])
+	}
+
+	count, err := binary.ReadUvarint(hr)
+	if err != nil {
+		return snapshotError(err)
+	}
+
+	// Only the most recent entries fit into the cache, but all of them
+	// still have to be read to verify the checksum.
+	skip := uint64(0)
+	if count > uint64(l.capacity) {
+		skip = count - uint64(l.capacity)
+	}
+	pairs := make([][2]int, 0, count-skip)
+	for i := uint64(0); i < count; i++ {
+		key, err := binary.ReadVarint(hr)
+		if err != nil {
+			return snapshotError(err)
+		}
+		value, err := binary.ReadVarint(hr)
+		if err != nil {
+			return snapshotError(err)
+		}
+		if i >= skip {
+			pairs = append(pairs, [2]int{int(key), int(value)})
+		}
+	}
+
+	sum := hr.h.Sum32()
+	var trailer [4]byte
+	for i := range trailer {
+		b, err := br.ReadByte()
+		if err != nil {
+			return snapshotError(err)
+		}
+		trailer[i] = b
+	}
+	if binary.BigEndian.Uint32(trailer[:]) != sum {
+		return ErrChecksumMismatch
+	}
+
+	l.Clear()
+	for _, p := range pairs {
+		l.Set(p[0], p[1])
+	}
+	return nil
+}
+
+func snapshotError(err error) error {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidSnapshot, err)
+}
+
+// hashingReader feeds every byte it reads into h.
+type hashingReader struct {
+	r io.ByteReader
+	h hash.Hash32
+}
+
+func (r *hashingReader) ReadByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err == nil {
+		_, _ = r.h.Write([]byte{b})
+	}
+	return b, err
+}
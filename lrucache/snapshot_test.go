@@ -0,0 +1,141 @@
+package lrucache
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func collect(c *LruCache) (keys, values []int) {
+	c.Range(func(k, v int) bool {
+		keys = append(keys, k)
+		values = append(values, v)
+		return true
+	})
+	return keys, values
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	t.Parallel()
+	c := New(5)
+
+	for i := 0; i < 5; i++ {
+		c.Set(i, -i*1000)
+	}
+	c.Get(0)
+	c.Get(3)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Snapshot(&buf))
+
+	restored := New(5)
+	restored.Set(42, 42)
+	require.NoError(t, restored.Restore(&buf))
+
+	keys, values := collect(restored)
+	require.Equal(t, []int{1, 2, 4, 0, 3}, keys)
+	require.Equal(t, []int{-1000, -2000, -4000, 0, -3000}, values)
+
+	_, ok := restored.Get(42)
+	require.False(t, ok)
+
+	// Both caches must evict the same keys from now on.
+	c.Set(5, 5)
+	restored.Set(5, 5)
+	wantKeys, _ := collect(c)
+	gotKeys, _ := collect(restored)
+	require.Equal(t, wantKeys, gotKeys)
+}
+
+func TestRestoreSmallerCapacity(t *testing.T) {
+	t.Parallel()
+	c := New(5)
+
+	for i := 0; i < 5; i++ {
+		c.Set(i, i)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Snapshot(&buf))
+
+	restored := New(2)
+	require.NoError(t, restored.Restore(&buf))
+
+	keys, _ := collect(restored)
+	require.Equal(t, []int{3, 4}, keys)
+}
+
+func TestSnapshotEmpty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, New(3).Snapshot(&buf))
+
+	restored := New(3)
+	restored.Set(1, 1)
+	require.NoError(t, restored.Restore(&buf))
+
+	keys, _ := collect(restored)
+	require.Empty(t, keys)
+}
+
+func TestRestoreCorrupted(t *testing.T) {
+	t.Parallel()
+	c := New(3)
+	c.Set(1, 10)
+	c.Set(2, 20)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Snapshot(&buf))
+	data := buf.Bytes()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{
+			name:    "empty",
+			data:    nil,
+			wantErr: ErrInvalidSnapshot,
+		},
+		{
+			name:    "bad_magic",
+			data:    append([]byte("LRUX"), data[4:]...),
+			wantErr: ErrInvalidSnapshot,
+		},
+		{
+			name:    "unknown_version",
+			data:    append(append([]byte("LRUC"), 99), data[5:]...),
+			wantErr: ErrUnsupportedVersion,
+		},
+		{
+			name:    "truncated",
+			data:    data[:len(data)-2],
+			wantErr: ErrInvalidSnapshot,
+		},
+		{
+			name: "flipped_bit",
+			data: func() []byte {
+				d := bytes.Clone(data)
+				d[7] ^= 1
+				return d
+			}(),
+			wantErr: ErrChecksumMismatch,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			restored := New(3)
+			restored.Set(7, 7)
+
+			err := restored.Restore(bytes.NewReader(tc.data))
+			require.ErrorIs(t, err, tc.wantErr)
+
+			keys, _ := collect(restored)
+			require.Equal(t, []int{7}, keys)
+		})
+	}
+}
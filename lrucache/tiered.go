@@ -0,0 +1,123 @@
+package lrucache
+
+import "fmt"
+
+// TieredCache is a two-tier cache: an in-memory LruCache in front of
+// a DiskStore.
+//
+// Elements evicted from memory spill to disk, and Get transparently
+// promotes them back. Every key lives in exactly one tier, so both tiers
+// together never hold more elements than the capacity given to NewTiered.
+//
+// TieredCache is not safe for concurrent use.
+type TieredCache struct {
+	mem  *LruCache
+	disk *DiskStore
+	// err is the first error that happened while spilling to disk.
+	err error
+}
+
+// NewTiered creates a TieredCache keeping up to memCapacity hottest
+// elements in memory and the rest, up to capacity elements in total,
+// in the disk log at path. Both are numbers of elements, not bytes.
+//
+// Elements left on disk by a previous run are available right away.
+func NewTiered(path string, memCapacity, capacity int) (*TieredCache, error) {
+	memCapacity = max(min(memCapacity, capacity), 0)
+
+	disk, err := OpenDiskStore(path, capacity-memCapacity)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &TieredCache{disk: disk}
+	t.mem = NewWithEvict(memCapacity, t.spill)
+	return t, nil
+}
+
+// Get returns value associated with the key, moving it to memory
+// if it was found on disk.
+//
+// The second value is a bool that is true if the key exists in the cache,
+// and false if not.
+func (t *TieredCache) Get(key int) (int, bool, error) {
+	if v, ok := t.mem.Get(key); ok {
+		return v, true, nil
+	}
+
+	v, ok, err := t.disk.Get(key)
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	if t.mem.Cap() == 0 {
+		return v, true, nil
+	}
+
+	if _, err := t.disk.Delete(key); err != nil {
+		return 0, false, err
+	}
+	t.mem.Set(key, v)
+	return v, true, t.takeErr()
+}
+
+// Set updates value associated with the key in memory tier.
+//
+// If there is no key in the cache new (key, value) pair is created.
+func (t *TieredCache) Set(key, value int) error {
+	if t.mem.Cap() == 0 {
+		return t.disk.Set(key, value)
+	}
+
+	if _, err := t.disk.Delete(key); err != nil {
+		return err
+	}
+	t.mem.Set(key, value)
+	return t.takeErr()
+}
+
+// Delete removes the key from both tiers.
+//
+// Returns true if the key was present.
+func (t *TieredCache) Delete(key int) (bool, error) {
+	if t.mem.Delete(key) {
+		return true, nil
+	}
+	return t.disk.Delete(key)
+}
+
+// Len returns the number of elements in both tiers.
+func (t *TieredCache) Len() int {
+	return t.mem.Len() + t.disk.Len()
+}
+
+// MemLen returns the number of elements in memory tier.
+func (t *TieredCache) MemLen() int {
+	return t.mem.Len()
+}
+
+// DiskLen returns the number of elements in disk tier.
+func (t *TieredCache) DiskLen() int {
+	return t.disk.Len()
+}
+
+// Close closes the disk tier.
+//
+// Elements that are only in memory are lost.
+func (t *TieredCache) Close() error {
+	return t.disk.Close()
+}
+
+func (t *TieredCache) spill(key, value int) {
+	if t.err != nil {
+		return
+	}
+	if err := t.disk.Set(key, value); err != nil {
+		t.err = fmt.Errorf("spill key %d to disk: %w", key, err)
+	}
+}
+
+func (t *TieredCache) takeErr() error {
+	err := t.err
+	t.err = nil
+	return err
+}
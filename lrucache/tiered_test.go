@@ -0,0 +1,103 @@
+package lrucache
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTieredSpillAndPromote(t *testing.T) {
+	t.Parallel()
+	c, err := NewTiered(filepath.Join(t.TempDir(), "tier.log"), 2, 4)
+	require.NoError(t, err)
+	defer c.Close()
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, c.Set(i, i*10))
+	}
+	require.Equal(t, 2, c.MemLen())
+	require.Equal(t, 2, c.DiskLen())
+
+	// Key 0 was spilled to disk and is promoted back, pushing key 2 out.
+	v, ok, err := c.Get(0)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 0, v)
+	require.True(t, c.mem.Contains(0))
+	require.True(t, c.disk.Contains(2))
+	require.False(t, c.disk.Contains(0))
+	require.Equal(t, 4, c.Len())
+}
+
+func TestTieredBudget(t *testing.T) {
+	t.Parallel()
+	c, err := NewTiered(filepath.Join(t.TempDir(), "tier.log"), 2, 5)
+	require.NoError(t, err)
+	defer c.Close()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, c.Set(i, i))
+	}
+	require.Equal(t, 5, c.Len())
+
+	for i := 0; i < 5; i++ {
+		_, ok, err := c.Get(i)
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+	for i := 5; i < 10; i++ {
+		v, ok, err := c.Get(i)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, i, v)
+	}
+	require.Equal(t, 5, c.Len())
+}
+
+func TestTieredUpdateAndDelete(t *testing.T) {
+	t.Parallel()
+	c, err := NewTiered(filepath.Join(t.TempDir(), "tier.log"), 1, 3)
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.NoError(t, c.Set(1, 1))
+	require.NoError(t, c.Set(2, 2))
+
+	// Key 1 is on disk now, updating it must not leave a stale copy there.
+	require.NoError(t, c.Set(1, 11))
+	require.Equal(t, 2, c.Len())
+
+	v, ok, err := c.Get(1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 11, v)
+
+	ok, err = c.Delete(2)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 1, c.Len())
+}
+
+func TestTieredWarmStart(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "tier.log")
+
+	c, err := NewTiered(path, 1, 3)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, c.Set(i, i*10))
+	}
+	require.NoError(t, c.Close())
+
+	c, err = NewTiered(path, 1, 3)
+	require.NoError(t, err)
+	defer c.Close()
+
+	for i := 0; i < 2; i++ {
+		v, ok, err := c.Get(i)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, i*10, v)
+	}
+}